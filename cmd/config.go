@@ -0,0 +1,237 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/xqsit94/glm/internal/config"
+	"github.com/xqsit94/glm/pkg/paths"
+
+	"github.com/spf13/cobra"
+)
+
+func ConfigCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "Manage configuration",
+		Long:  "Read and write settings in the GLM config file",
+	}
+
+	cmd.AddCommand(configGetCmd())
+	cmd.AddCommand(configSetCmd())
+	cmd.AddCommand(configUnsetCmd())
+	cmd.AddCommand(configListCmd())
+	cmd.AddCommand(configPathCmd())
+	cmd.AddCommand(configEditCmd())
+
+	return cmd
+}
+
+func configGetCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "get <key>",
+		Short: "Print a config value",
+		Long:  "Print the value of a config key (valid keys: " + strings.Join(config.Keys(), ", ") + ")",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := config.Load()
+			if err != nil {
+				return err
+			}
+
+			value, err := cfg.Get(args[0])
+			if err != nil {
+				return err
+			}
+
+			fmt.Println(value)
+			return nil
+		},
+	}
+}
+
+func configSetCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "set <key> <value>",
+		Short: "Set a config value",
+		Long:  "Set a config value (valid keys: " + strings.Join(config.Keys(), ", ") + ")",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return updateConfig(func(cfg *config.Config) error {
+				return cfg.Set(args[0], args[1])
+			}, fmt.Sprintf("%s has been set to: %s", args[0], displayValue(args[0], args[1])))
+		},
+	}
+}
+
+func configUnsetCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "unset <key>",
+		Short: "Remove a config value",
+		Long:  "Remove a config value so the default applies again",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return updateConfig(func(cfg *config.Config) error {
+				return cfg.Unset(args[0])
+			}, fmt.Sprintf("%s has been unset", args[0]))
+		},
+	}
+}
+
+func configListCmd() *cobra.Command {
+	var showOrigin bool
+
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List config values",
+		Long:  "List effective config values, including overrides from a project .glm.json/.glm.toml (secrets are masked)",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := config.Load()
+			if err != nil {
+				return err
+			}
+
+			project, err := config.LoadProject()
+			if err != nil {
+				return err
+			}
+
+			for _, setting := range config.EffectiveSettings(cfg, project) {
+				value := setting.Value
+				if setting.Secret {
+					value = config.MaskSecret(value)
+				}
+
+				if showOrigin {
+					fmt.Printf("%-40s %s=%s\n", setting.Origin, setting.Key, value)
+				} else {
+					fmt.Printf("%s=%s\n", setting.Key, value)
+				}
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&showOrigin, "show-origin", false, "Show which file each value comes from")
+
+	return cmd
+}
+
+func configPathCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "path",
+		Short: "Print the config file path",
+		Long:  "Print the location of the GLM config file",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			fmt.Println(paths.GetConfigPath())
+			return nil
+		},
+	}
+}
+
+func configEditCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "edit",
+		Short: "Edit the config file in $EDITOR",
+		Long:  "Open the config file in $EDITOR and validate the result before saving it",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runConfigEdit()
+		},
+	}
+}
+
+func updateConfig(change func(cfg *config.Config) error, message string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+
+	if err := change(cfg); err != nil {
+		return err
+	}
+
+	if err := config.Save(cfg); err != nil {
+		return err
+	}
+
+	fmt.Printf("✅ %s\n", message)
+	return nil
+}
+
+func displayValue(key, value string) string {
+	if config.IsSecretKey(key) {
+		return config.MaskSecret(value)
+	}
+	return value
+}
+
+func runConfigEdit() error {
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+
+	data, err := config.Marshal(cfg)
+	if err != nil {
+		return err
+	}
+
+	tmpFile, err := os.CreateTemp("", "glm-config-*.json")
+	if err != nil {
+		return fmt.Errorf("failed to create temp file: %v", err)
+	}
+	defer os.Remove(tmpFile.Name())
+
+	if _, err := tmpFile.Write(data); err != nil {
+		tmpFile.Close()
+		return fmt.Errorf("failed to write temp file: %v", err)
+	}
+	tmpFile.Close()
+
+	reader := bufio.NewReader(os.Stdin)
+	for {
+		editorCmd := exec.Command("sh", "-c", editor+` "$1"`, "sh", tmpFile.Name())
+		editorCmd.Stdin = os.Stdin
+		editorCmd.Stdout = os.Stdout
+		editorCmd.Stderr = os.Stderr
+		if err := editorCmd.Run(); err != nil {
+			return fmt.Errorf("editor exited with error: %v", err)
+		}
+
+		edited, err := os.ReadFile(tmpFile.Name())
+		if err != nil {
+			return fmt.Errorf("failed to read edited config: %v", err)
+		}
+
+		newCfg, err := config.Parse(edited)
+		if err == nil {
+			if err := config.Save(newCfg); err != nil {
+				return err
+			}
+			fmt.Println("✅ Config has been saved.")
+			return nil
+		}
+
+		fmt.Printf("❌ Invalid config: %v\n", err)
+		fmt.Print("Re-open the editor? (Y/n): ")
+		response, readErr := reader.ReadString('\n')
+		response = strings.ToLower(strings.TrimSpace(response))
+		if readErr != nil || response == "n" || response == "no" {
+			return fmt.Errorf("config was not saved")
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/xqsit94/glm/internal/doctor"
+	"github.com/xqsit94/glm/internal/runner"
+
+	"github.com/spf13/cobra"
+)
+
+func DoctorCmd() *cobra.Command {
+	var opts doctor.Options
+	var jsonOutput bool
+
+	cmd := &cobra.Command{
+		Use:   "doctor",
+		Short: "Diagnose your GLM setup",
+		Long:  "Check Claude Code, Node.js, the token, config permissions, Claude settings, the shell environment and the API endpoint. Exits non-zero if any check fails.",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runDoctor(opts, jsonOutput)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.Profile, "profile", "", "Provider profile to check")
+	cmd.Flags().StringVar(&opts.TokenName, "token-name", "", "Stored token to check")
+	cmd.Flags().BoolVar(&opts.SkipNetwork, "offline", false, "Skip the API endpoint check")
+	cmd.Flags().BoolVar(&jsonOutput, "json", false, "Print the report as JSON")
+
+	return cmd
+}
+
+func runDoctor(opts doctor.Options, jsonOutput bool) error {
+	report := doctor.Run(opts)
+
+	if jsonOutput {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal report: %v", err)
+		}
+		fmt.Println(string(data))
+	} else {
+		fmt.Println("🩺 GLM doctor")
+		fmt.Println()
+
+		icons := map[doctor.Status]string{
+			doctor.StatusOK:   "✅",
+			doctor.StatusWarn: "⚠️ ",
+			doctor.StatusFail: "❌",
+		}
+		for _, check := range report.Checks {
+			fmt.Printf("%s %-16s %s\n", icons[check.Status], check.Name, check.Message)
+			if check.Hint != "" && check.Status != doctor.StatusOK {
+				fmt.Printf("   %-16s 💡 %s\n", "", check.Hint)
+			}
+		}
+
+		fmt.Println()
+		fmt.Printf("%d passed, %d warnings, %d failed\n",
+			report.Count(doctor.StatusOK), report.Count(doctor.StatusWarn), report.Count(doctor.StatusFail))
+	}
+
+	if report.Failed() {
+		return &runner.ExitError{Code: 1}
+	}
+	return nil
+}
@@ -3,8 +3,9 @@ package cmd
 import (
 	"fmt"
 
+	"github.com/xqsit94/glm/internal/config"
 	"github.com/xqsit94/glm/internal/glm"
-	"github.com/xqsit94/glm/internal/token"
+	"github.com/xqsit94/glm/internal/session"
 
 	"github.com/spf13/cobra"
 )
@@ -21,17 +22,19 @@ func EnableCmd() *cobra.Command {
 			fmt.Println()
 
 			model, _ := cmd.Flags().GetString("model")
+			profile, _ := cmd.Flags().GetString("profile")
 
-			authToken, err := token.Get()
+			sess, err := session.New(session.Options{Profile: profile, Model: model})
 			if err != nil {
 				return err
 			}
 
-			return glm.Enable(model, authToken)
+			return glm.Enable(sess.BaseURL, sess.Model, sess.Token)
 		},
 	}
 
-	cmd.Flags().StringP("model", "m", defaultModel, fmt.Sprintf("GLM model to use (default: %s)", defaultModel))
+	cmd.Flags().StringP("model", "m", "", fmt.Sprintf("GLM model to use (default: %s)", config.BuiltinModel))
+	cmd.Flags().String("profile", "", "Provider profile to use")
 
 	return cmd
 }
@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/xqsit94/glm/internal/session"
+
+	"github.com/spf13/cobra"
+)
+
+func EnvCmd() *cobra.Command {
+	var opts session.Options
+	var shell string
+	var format string
+	var reveal bool
+
+	cmd := &cobra.Command{
+		Use:     "env",
+		Aliases: []string{"shell-init"},
+		Short:   "Print the GLM session environment",
+		Long: `Print the environment variables 'glm' injects into Claude, for use in a shell or other tools.
+
+  eval "$(glm env --reveal)"              # bash/zsh
+  glm env --shell fish --reveal | source  # fish
+  glm env --format dotenv --reveal > .env # docker-compose, IDE run configs
+
+The token is masked unless --reveal is given.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if shell == "" {
+				shell = session.DetectShell(os.Getenv("SHELL"))
+			}
+			return runEnv(opts, format, shell, reveal)
+		},
+	}
+
+	cmd.Flags().StringVarP(&opts.Model, "model", "m", "", "GLM model to export")
+	cmd.Flags().StringVar(&opts.Profile, "profile", "", "Provider profile to export")
+	cmd.Flags().StringVar(&opts.TokenName, "token-name", "", "Stored token to export")
+	addModelMapFlags(cmd, &opts.Models)
+	cmd.Flags().StringVar(&shell, "shell", "", fmt.Sprintf("Shell syntax for --format shell (%s; default: from $SHELL)", strings.Join(session.Shells, ", ")))
+	cmd.Flags().StringVar(&format, "format", "shell", fmt.Sprintf("Output format (%s)", strings.Join(session.Formats, ", ")))
+	cmd.Flags().BoolVar(&reveal, "reveal", false, "Print the token in full instead of masking it")
+
+	return cmd
+}
+
+func runEnv(opts session.Options, format, shell string, reveal bool) error {
+	// The output is usually consumed by eval, where a prompt would be
+	// swallowed and leave the shell waiting for input.
+	opts.NoPrompt = true
+
+	sess, err := session.New(opts)
+	if err != nil {
+		return err
+	}
+
+	reportProject(sess)
+
+	output, err := sess.Export(format, shell, reveal)
+	if err != nil {
+		return err
+	}
+
+	fmt.Print(output)
+
+	if format != "shell" {
+		for _, name := range sess.Unset() {
+			fmt.Fprintf(os.Stderr, "⚠️  %s is set in your shell and conflicts with the GLM session; unset it before loading this environment\n", name)
+		}
+	}
+
+	if !reveal {
+		fmt.Fprintln(os.Stderr, "💡 The token is masked. Pass --reveal to export it in full.")
+	}
+
+	return nil
+}
@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"github.com/xqsit94/glm/internal/runner"
+	"github.com/xqsit94/glm/internal/session"
+
+	"github.com/spf13/cobra"
+)
+
+func ExecCmd() *cobra.Command {
+	var opts session.Options
+
+	cmd := &cobra.Command{
+		Use:   "exec [flags] -- <command> [args...]",
+		Short: "Run a command with the GLM environment",
+		Long:  "Run any command (Anthropic SDK scripts, test harnesses, ...) with the same environment 'glm' injects into Claude. The command's exit status is passed through.",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			sess, err := session.New(opts)
+			if err != nil {
+				return err
+			}
+
+			reportProject(sess)
+			return runner.Exec(args[0], args[1:], childEnv(sess))
+		},
+	}
+
+	cmd.Flags().SetInterspersed(false)
+	cmd.Flags().StringVarP(&opts.Model, "model", "m", "", "GLM model to use")
+	cmd.Flags().StringVar(&opts.Profile, "profile", "", "Provider profile to use")
+	cmd.Flags().StringVar(&opts.TokenName, "token-name", "", "Stored token to use")
+	addModelMapFlags(cmd, &opts.Models)
+
+	return cmd
+}
@@ -0,0 +1,27 @@
+package cmd
+
+import (
+	"github.com/xqsit94/glm/internal/glm"
+
+	"github.com/spf13/cobra"
+)
+
+func MigrateCmd() *cobra.Command {
+	var dryRun bool
+	var assumeYes bool
+
+	cmd := &cobra.Command{
+		Use:   "migrate",
+		Short: "Clean up leftover v1.0 configuration",
+		Long:  "Remove the ANTHROPIC_* env entries a v1.0 'glm enable' wrote to ~/.claude/settings.json, keeping every other Claude Code setting and a backup",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return glm.Migrate(dryRun, assumeYes)
+		},
+	}
+
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show what would change without modifying anything")
+	cmd.Flags().BoolVarP(&assumeYes, "yes", "y", false, "Apply without confirmation prompt")
+
+	return cmd
+}
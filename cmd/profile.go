@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"github.com/xqsit94/glm/internal/config"
+	"github.com/xqsit94/glm/internal/profile"
+
+	"github.com/spf13/cobra"
+)
+
+func ProfileCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "profile",
+		Short: "Manage provider profiles",
+		Long:  "Manage named provider profiles (base URL, token source, default model and extra environment)",
+	}
+
+	cmd.AddCommand(profileAddCmd())
+	cmd.AddCommand(profileListCmd())
+	cmd.AddCommand(profileUseCmd())
+	cmd.AddCommand(profileRemoveCmd())
+
+	return cmd
+}
+
+func profileAddCmd() *cobra.Command {
+	var p config.Profile
+	var envPairs []string
+	var force bool
+
+	cmd := &cobra.Command{
+		Use:   "add <name>",
+		Short: "Add a provider profile",
+		Long:  "Add or overwrite a named provider profile pointing at an Anthropic-compatible endpoint",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			env, err := config.ParseEnvPairs(envPairs)
+			if err != nil {
+				return err
+			}
+			p.Env = env
+
+			return profile.Add(args[0], p, force)
+		},
+	}
+
+	cmd.Flags().StringVar(&p.BaseURL, "base-url", "", "Anthropic-compatible base URL (required)")
+	cmd.Flags().StringVar(&p.TokenName, "token-name", "", "Stored token to use for this profile (see 'glm token list')")
+	cmd.Flags().StringVar(&p.TokenEnv, "token-env", "", "Environment variable to read the token from (default: stored token)")
+	cmd.Flags().StringVar(&p.AuthMode, "auth-mode", "", "How to send the token: key or jwt (default: the global auth_mode)")
+	cmd.Flags().StringVarP(&p.DefaultModel, "model", "m", "", "Default model for this profile")
+	cmd.Flags().StringArrayVar(&envPairs, "env", nil, "Extra environment variable as KEY=VALUE (repeatable)")
+	cmd.Flags().BoolVar(&force, "force", false, "Overwrite an existing profile")
+	cmd.MarkFlagRequired("base-url")
+
+	return cmd
+}
+
+func profileListCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List provider profiles",
+		Long:  "List built-in and custom provider profiles (the active one is marked with *)",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return profile.List()
+		},
+	}
+}
+
+func profileUseCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "use <name>",
+		Short: "Set the active provider profile",
+		Long:  "Set the provider profile used when no --profile flag is given",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return profile.Use(args[0])
+		},
+	}
+}
+
+func profileRemoveCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:     "remove <name>",
+		Aliases: []string{"rm"},
+		Short:   "Remove a provider profile",
+		Long:    "Remove a custom provider profile",
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return profile.Remove(args[0])
+		},
+	}
+}
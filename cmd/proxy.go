@@ -0,0 +1,121 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/xqsit94/glm/internal/config"
+	"github.com/xqsit94/glm/internal/proxy"
+	"github.com/xqsit94/glm/internal/session"
+	"github.com/xqsit94/glm/pkg/paths"
+
+	"github.com/spf13/cobra"
+)
+
+func ProxyCmd() *cobra.Command {
+	var opts session.Options
+	var host string
+	var port int
+	var localKey string
+	var logPath string
+
+	cmd := &cobra.Command{
+		Use:   "proxy",
+		Short: "Run a local Anthropic-compatible proxy",
+		Long:  "Run a local reverse proxy that forwards requests to the configured upstream, injects the stored token and logs each request",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runProxy(opts, fmt.Sprintf("%s:%d", host, port), localKey, logPath)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.Profile, "profile", "", "Provider profile to forward to")
+	cmd.Flags().StringVar(&opts.TokenName, "token-name", "", "Stored token to inject")
+	cmd.Flags().StringVar(&host, "host", "127.0.0.1", "Address to listen on")
+	cmd.Flags().IntVar(&port, "port", 8787, "Port to listen on")
+	cmd.Flags().StringVar(&localKey, "local-key", "", "Key clients must present (default: randomly generated)")
+	cmd.Flags().StringVar(&logPath, "log", paths.GetProxyLogPath(), "Request log file (JSON lines)")
+
+	return cmd
+}
+
+func runProxy(opts session.Options, addr, localKey, logPath string) error {
+	sess, err := session.New(opts)
+	if err != nil {
+		return err
+	}
+
+	if localKey == "" {
+		localKey, err = proxy.GenerateLocalKey()
+		if err != nil {
+			return err
+		}
+	}
+
+	srv, err := proxy.New(proxy.Options{
+		Upstream: sess.BaseURL,
+		Token:    sess.Credential(),
+		LocalKey: localKey,
+		LogPath:  logPath,
+	})
+	if err != nil {
+		return err
+	}
+	defer srv.Close()
+
+	proxyURL, err := srv.Start(addr)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("🛰️  Proxy listening on %s\n", proxyURL)
+	fmt.Printf("🌐 Forwarding to: %s (profile %s)\n", sess.BaseURL, sess.Profile)
+	if sess.AuthMode == config.AuthModeJWT {
+		fmt.Printf("🔐 Signing upstream requests with short-lived JWTs (TTL %s)\n", sess.AuthTTL)
+	}
+	fmt.Printf("🔑 Local key: %s\n", localKey)
+	fmt.Printf("📝 Logging requests to: %s\n", logPath)
+	fmt.Println()
+	fmt.Println("💡 Point clients at the proxy with:")
+	fmt.Printf("   export ANTHROPIC_BASE_URL=%s\n", proxyURL)
+	fmt.Printf("   export ANTHROPIC_AUTH_TOKEN=%s\n", localKey)
+	fmt.Println()
+	fmt.Println("Press Ctrl+C to stop.")
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	<-signals
+
+	fmt.Println("\n👋 Proxy stopped.")
+	return nil
+}
+
+func startSessionProxy(sess *session.Session) (*proxy.Server, error) {
+	localKey, err := proxy.GenerateLocalKey()
+	if err != nil {
+		return nil, err
+	}
+
+	srv, err := proxy.New(proxy.Options{
+		Upstream: sess.BaseURL,
+		Token:    sess.Credential(),
+		LocalKey: localKey,
+		LogPath:  paths.GetProxyLogPath(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	proxyURL, err := srv.Start("127.0.0.1:0")
+	if err != nil {
+		srv.Close()
+		return nil, err
+	}
+
+	sess.BaseURL = proxyURL
+	sess.AuthToken = localKey
+
+	return srv, nil
+}
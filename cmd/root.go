@@ -4,41 +4,68 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"sort"
+	"strings"
 
-	"github.com/xqsit94/glm/internal/token"
+	"github.com/xqsit94/glm/internal/config"
+	"github.com/xqsit94/glm/internal/glm"
+	"github.com/xqsit94/glm/internal/runner"
+	"github.com/xqsit94/glm/internal/session"
+	"github.com/xqsit94/glm/pkg/paths"
 
 	"github.com/spf13/cobra"
+	"golang.org/x/term"
 )
 
-const (
-	version      = "1.1.0"
-	defaultModel = "glm-4.6"
-)
+const version = "1.1.0"
 
 func RootCmd() *cobra.Command {
-	var model string
+	var opts session.Options
+	var viaProxy bool
 
 	cmd := &cobra.Command{
-		Use:     "glm",
+		Use:     "glm [flags] [-- claude args...]",
 		Short:   "GLM Claude settings management CLI",
 		Long:    "A CLI tool to launch Claude with GLM settings using temporary session-based configuration",
 		Version: version,
+		Args:    cobra.ArbitraryArgs,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+			return paths.Check()
+		},
+		SilenceErrors: true,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runDefaultAction(model)
+			return runDefaultAction(opts, viaProxy, args)
 		},
 	}
 
-	cmd.Flags().StringVarP(&model, "model", "m", defaultModel, "GLM model to use for this session")
+	cmd.Flags().SetInterspersed(false)
+	cmd.Flags().StringVarP(&opts.Model, "model", "m", "", "GLM model to use for this session (overrides GLM_MODEL and configured defaults)")
+	cmd.Flags().StringVar(&opts.Profile, "profile", "", "Provider profile to use for this session")
+	cmd.Flags().StringVar(&opts.TokenName, "token-name", "", "Stored token to use for this session")
+	cmd.Flags().BoolVar(&viaProxy, "via-proxy", false, "Route requests through a local proxy so Claude never sees the real token")
+	addModelMapFlags(cmd, &opts.Models)
 
 	return cmd
 }
 
-func runDefaultAction(model string) error {
+func addModelMapFlags(cmd *cobra.Command, models *config.ModelMap) {
+	cmd.Flags().StringVar(&models.SmallFast, "fast-model", "", "Model for background tasks (ANTHROPIC_SMALL_FAST_MODEL)")
+	cmd.Flags().StringVar(&models.Opus, "opus-model", "", "Model used when Claude Code asks for opus")
+	cmd.Flags().StringVar(&models.Sonnet, "sonnet-model", "", "Model used when Claude Code asks for sonnet")
+	cmd.Flags().StringVar(&models.Haiku, "haiku-model", "", "Model used when Claude Code asks for haiku")
+}
+
+func runDefaultAction(opts session.Options, viaProxy bool, claudeArgs []string) error {
 	fmt.Println("🚀 Launching Claude with GLM...")
 
-	authToken, err := token.Get()
+	if term.IsTerminal(int(os.Stdin.Fd())) {
+		glm.OfferMigration(version)
+	}
+
+	sess, err := session.New(opts)
 	if err != nil {
-		return fmt.Errorf("failed to get authentication token: %v", err)
+		return err
 	}
 
 	if _, err := exec.LookPath("claude"); err != nil {
@@ -47,22 +74,69 @@ func runDefaultAction(model string) error {
 		return fmt.Errorf("claude command not found")
 	}
 
-	fmt.Printf("📝 Using model: %s\n", model)
+	claudeArgs = append(append([]string{}, sess.ClaudeArgs...), claudeArgs...)
+
+	reportProject(sess)
+
+	fmt.Printf("🌐 Using profile: %s (%s)\n", sess.Profile, sess.BaseURL)
+	fmt.Printf("📝 Using model: %s (from %s)\n", sess.Model, sess.ModelSource)
+	fmt.Printf("🧩 Model mapping: opus=%s, sonnet=%s, haiku=%s, small/fast=%s\n",
+		sess.Models.Opus, sess.Models.Sonnet, sess.Models.Haiku, sess.Models.SmallFast)
+
+	if sess.AuthMode == config.AuthModeJWT {
+		if viaProxy {
+			fmt.Printf("🔐 Signing requests with short-lived JWTs (TTL %s), refreshed by the proxy\n", sess.AuthTTL)
+		} else {
+			fmt.Printf("🔐 Using a short-lived JWT that expires in %s\n", sess.AuthTTL)
+			fmt.Println("💡 Use --via-proxy to refresh it automatically during long sessions.")
+		}
+	}
+
+	if viaProxy {
+		srv, err := startSessionProxy(sess)
+		if err != nil {
+			return err
+		}
+		defer srv.Close()
+
+		fmt.Printf("🛰️  Routing requests through local proxy: %s\n", sess.BaseURL)
+		fmt.Printf("📝 Logging requests to: %s\n", paths.GetProxyLogPath())
+		fmt.Println("🎯 Starting Claude Code with temporary GLM configuration...")
+
+		return runner.Run("claude", claudeArgs, childEnv(sess))
+	}
+
 	fmt.Println("🎯 Starting Claude Code with temporary GLM configuration...")
 
-	cmd := exec.Command("claude")
-	cmd.Stdin = os.Stdin
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-	cmd.Env = append(os.Environ(),
-		"ANTHROPIC_BASE_URL=https://open.bigmodel.cn/api/anthropic",
-		"ANTHROPIC_AUTH_TOKEN="+authToken,
-		"ANTHROPIC_MODEL="+model,
-	)
-
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("failed to run claude: %v", err)
+	return runner.Exec("claude", claudeArgs, childEnv(sess))
+}
+
+// reportProject names the project file in effect and what it adds, so a
+// file from a cloned repository is never applied unnoticed.
+func reportProject(sess *session.Session) {
+	project := sess.Project
+	if project == nil || project.Path == "" {
+		return
 	}
 
-	return nil
+	fmt.Fprintf(os.Stderr, "📁 Using project config: %s\n", project.Path)
+	if len(project.Env) > 0 {
+		keys := make([]string, 0, len(project.Env))
+		for key := range project.Env {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		fmt.Fprintf(os.Stderr, "   env: %s\n", strings.Join(keys, ", "))
+	}
+	if len(project.ClaudeArgs) > 0 {
+		fmt.Fprintf(os.Stderr, "   claude args: %s\n", strings.Join(project.ClaudeArgs, " "))
+	}
+}
+
+func childEnv(sess *session.Session) []string {
+	env, masked := sess.Environ()
+	for _, name := range masked {
+		fmt.Fprintf(os.Stderr, "⚠️  Ignoring %s from your shell: it conflicts with the GLM session settings\n", name)
+	}
+	return env
 }
@@ -1,6 +1,8 @@
 package cmd
 
 import (
+	"time"
+
 	"github.com/xqsit94/glm/internal/token"
 
 	"github.com/spf13/cobra"
@@ -9,46 +11,191 @@ import (
 func TokenCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "token",
-		Short: "Manage authentication token",
-		Long:  "Manage your Anthropic authentication token",
+		Short: "Manage authentication tokens",
+		Long:  "Manage your Anthropic authentication tokens",
 	}
 
 	cmd.AddCommand(tokenSetCmd())
 	cmd.AddCommand(tokenShowCmd())
+	cmd.AddCommand(tokenVerifyCmd())
+	cmd.AddCommand(tokenListCmd())
+	cmd.AddCommand(tokenUseCmd())
+	cmd.AddCommand(tokenRemoveCmd())
 	cmd.AddCommand(tokenClearCmd())
+	cmd.AddCommand(tokenEncryptCmd())
+	cmd.AddCommand(tokenDecryptCmd())
+	cmd.AddCommand(tokenUnlockCmd())
+	cmd.AddCommand(tokenLockCmd())
+	cmd.AddCommand(tokenAgentCmd())
 
 	return cmd
 }
 
 func tokenSetCmd() *cobra.Command {
-	return &cobra.Command{
+	var opts token.SetOptions
+
+	cmd := &cobra.Command{
 		Use:   "set",
 		Short: "Set authentication token",
-		Long:  "Set your Anthropic authentication token interactively",
+		Long:  "Set an Anthropic authentication token interactively (the active token unless --name is given). The token is checked against the API first and is not saved if it is rejected.",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return token.Set()
+			return token.Set(opts)
 		},
 	}
+
+	cmd.Flags().StringVar(&opts.Name, "name", "", "Name to store the token under (default: the active token)")
+	cmd.Flags().StringVar(&opts.Profile, "profile", "", "Profile whose endpoint verifies the token (default: the profile using it, or the active one)")
+	cmd.Flags().BoolVar(&opts.NoVerify, "no-verify", false, "Save the token without checking it against the API")
+
+	return cmd
+}
+
+func tokenVerifyCmd() *cobra.Command {
+	var name string
+	var profile string
+
+	cmd := &cobra.Command{
+		Use:   "verify",
+		Short: "Check a token against the API",
+		Long:  "Send a minimal authenticated request and report whether the token is valid, unauthorized, for the wrong region or endpoint, rate limited, or unreachable",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return token.Verify(name, profile)
+		},
+	}
+
+	cmd.Flags().StringVar(&name, "name", "", "Stored token to verify (default: the current token)")
+	cmd.Flags().StringVar(&profile, "profile", "", "Profile whose endpoint to verify against")
+
+	return cmd
 }
 
 func tokenShowCmd() *cobra.Command {
 	return &cobra.Command{
 		Use:   "show",
 		Short: "Show current token",
-		Long:  "Display the current authentication token (masked)",
+		Long:  "Display the current authentication token and all stored token names (masked)",
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return token.Show()
 		},
 	}
 }
 
+func tokenListCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List stored tokens",
+		Long:  "List stored tokens with masked values (the active one is marked with *)",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return token.List()
+		},
+	}
+}
+
+func tokenUseCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "use <name>",
+		Short: "Set the active token",
+		Long:  "Set the stored token used when neither a profile nor --token-name picks one",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return token.Use(args[0])
+		},
+	}
+}
+
+func tokenRemoveCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:     "rm <name>",
+		Aliases: []string{"remove"},
+		Short:   "Remove a stored token",
+		Long:    "Remove a stored token by name",
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return token.Remove(args[0])
+		},
+	}
+}
+
 func tokenClearCmd() *cobra.Command {
 	return &cobra.Command{
 		Use:   "clear",
-		Short: "Clear authentication token",
-		Long:  "Remove the stored authentication token",
+		Short: "Clear all authentication tokens",
+		Long:  "Remove every stored authentication token (profiles and other settings are kept)",
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return token.Clear()
 		},
 	}
 }
+
+func tokenEncryptCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "encrypt",
+		Short: "Encrypt stored tokens with a passphrase",
+		Long:  "Encrypt all stored tokens at rest with a passphrase (AES-GCM with a PBKDF2-derived key). Set GLM_PASSPHRASE to run non-interactively.",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return token.Encrypt()
+		},
+	}
+}
+
+func tokenDecryptCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "decrypt",
+		Short: "Store tokens in plaintext again",
+		Long:  "Decrypt all stored tokens and remove the passphrase",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return token.Decrypt()
+		},
+	}
+}
+
+func tokenUnlockCmd() *cobra.Command {
+	var ttl time.Duration
+
+	cmd := &cobra.Command{
+		Use:   "unlock",
+		Short: "Cache the unlocked tokens for a while",
+		Long:  "Ask for the passphrase once and keep the key in a local agent so glm does not prompt again until it expires",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return token.Unlock(ttl)
+		},
+	}
+
+	cmd.Flags().DurationVar(&ttl, "ttl", token.DefaultUnlockTTL, "How long to keep the tokens unlocked")
+
+	return cmd
+}
+
+func tokenLockCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "lock",
+		Short: "Forget the cached passphrase",
+		Long:  "Stop the unlock agent started by 'glm token unlock'",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return token.Lock()
+		},
+	}
+}
+
+func tokenAgentCmd() *cobra.Command {
+	var ttl time.Duration
+
+	cmd := &cobra.Command{
+		Use:    "agent",
+		Short:  "Serve the unlocked key (started by 'glm token unlock')",
+		Hidden: true,
+		Args:   cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return token.RunAgent(ttl)
+		},
+	}
+
+	cmd.Flags().DurationVar(&ttl, "ttl", token.DefaultUnlockTTL, "How long to keep the key")
+
+	return cmd
+}
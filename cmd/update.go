@@ -1,10 +1,14 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
+	"os"
 	"strings"
 
+	"github.com/xqsit94/glm/internal/config"
 	"github.com/xqsit94/glm/internal/updater"
+	"github.com/xqsit94/glm/pkg/paths"
 
 	"github.com/spf13/cobra"
 )
@@ -12,38 +16,93 @@ import (
 func UpdateCmd() *cobra.Command {
 	var checkOnly bool
 	var force bool
+	var rollback bool
+	var list bool
+	var allowUnverified bool
+	var opts updater.CheckOptions
 
 	cmd := &cobra.Command{
 		Use:   "update",
 		Short: "Update GLM to the latest version",
-		Long:  "Check for updates and install the latest version of GLM from GitHub",
+		Long:  "Check for updates and install the latest version of GLM from GitHub or the mirror set with update_mirror or GLM_UPDATE_MIRROR",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runUpdate(checkOnly, force)
+			if rollback {
+				return runRollback()
+			}
+			if err := updater.ValidateChannel(opts.Channel); err != nil {
+				return err
+			}
+			if list {
+				return runListReleases(opts.Channel)
+			}
+			if allowUnverified && opts.Version == "" {
+				return fmt.Errorf("--allow-unverified can only be used with --version")
+			}
+			return runUpdate(opts, checkOnly, force, allowUnverified)
 		},
 	}
 
 	cmd.Flags().BoolVar(&checkOnly, "check", false, "Only check for updates without installing")
 	cmd.Flags().BoolVar(&force, "force", false, "Update without confirmation prompt")
+	cmd.Flags().BoolVar(&rollback, "rollback", false, "Restore the version that was installed before the last update")
+	cmd.Flags().StringVar(&opts.Version, "version", "", "Install a specific version, e.g. v1.0.3 (can downgrade)")
+	cmd.Flags().StringVar(&opts.Channel, "channel", updater.ChannelStable, "Release channel: stable or beta (includes prereleases)")
+	cmd.Flags().BoolVar(&list, "list", false, "List available versions with their release dates")
+	cmd.Flags().BoolVar(&allowUnverified, "allow-unverified", false, "With --version, install an old release that has no signed checksums (not verified!)")
+	cmd.MarkFlagsMutuallyExclusive("rollback", "check")
+	cmd.MarkFlagsMutuallyExclusive("rollback", "version")
+	cmd.MarkFlagsMutuallyExclusive("rollback", "list")
+	cmd.MarkFlagsMutuallyExclusive("version", "channel")
+	cmd.MarkFlagsMutuallyExclusive("version", "list")
 
 	return cmd
 }
 
-func runUpdate(checkOnly, force bool) error {
+func runUpdate(opts updater.CheckOptions, checkOnly, force, allowUnverified bool) error {
 	fmt.Println("🔍 Checking for updates...")
 	fmt.Printf("📌 Current version: %s\n", version)
 
-	info, err := updater.CheckForUpdate(version)
+	source, err := releaseSource()
+	if err != nil {
+		return err
+	}
+
+	if !checkOnly {
+		unlock, err := updater.Lock()
+		if err != nil {
+			fmt.Printf("❌ %v\n", err)
+			return err
+		}
+		defer unlock()
+	}
+
+	info, err := updater.CheckForUpdate(source, version, opts)
 	if err != nil {
+		if errors.Is(err, updater.ErrVersionNotFound) {
+			fmt.Printf("❌ %v\n", err)
+			return err
+		}
 		fmt.Println("❌ Unable to check for updates. Please check your internet connection.")
 		return fmt.Errorf("update check failed: %v", err)
 	}
 
 	if !info.HasUpdate {
-		fmt.Println("✅ You're already running the latest version!")
+		if opts.Version != "" {
+			fmt.Printf("✅ Version %s is already installed.\n", info.LatestVersion)
+		} else {
+			fmt.Println("✅ You're already running the latest version!")
+		}
 		return nil
 	}
 
-	fmt.Printf("✨ Latest version: %s available!\n\n", info.LatestVersion)
+	switch {
+	case info.Downgrade:
+		fmt.Printf("⏬ Version %s is older than the current version.\n\n", info.LatestVersion)
+	case info.Prerelease:
+		fmt.Printf("🧪 Prerelease %s available!\n\n", info.LatestVersion)
+	default:
+		fmt.Printf("✨ Latest version: %s available!\n\n", info.LatestVersion)
+	}
 
 	releaseNotes := updater.FormatReleaseNotes(info.ReleaseNotes, 10)
 	if releaseNotes != "" {
@@ -59,12 +118,20 @@ func runUpdate(checkOnly, force bool) error {
 	fmt.Printf("🔗 View full release notes: %s\n\n", info.ReleaseURL)
 
 	if checkOnly {
-		fmt.Printf("💡 Run 'glm update' to install version %s\n", info.LatestVersion)
+		if opts.Version != "" || info.Prerelease {
+			fmt.Printf("💡 Run 'glm update --version %s' to install it\n", info.LatestVersion)
+		} else {
+			fmt.Printf("💡 Run 'glm update' to install version %s\n", info.LatestVersion)
+		}
 		return nil
 	}
 
 	if !force {
-		fmt.Printf("Would you like to update to %s? (y/N): ", info.LatestVersion)
+		action := "update"
+		if info.Downgrade {
+			action = "downgrade"
+		}
+		fmt.Printf("Would you like to %s to %s? (y/N): ", action, info.LatestVersion)
 		var response string
 		fmt.Scanln(&response)
 
@@ -81,7 +148,38 @@ func runUpdate(checkOnly, force bool) error {
 		return err
 	}
 
-	fmt.Printf("\n📥 Downloading glm %s for %s/%s...\n", info.LatestVersion, osName, arch)
+	binaryName := updater.BinaryName(osName, arch)
+
+	fmt.Println("\n🔐 Fetching release checksums...")
+	var expectedSHA256 string
+	checksums, err := updater.FetchChecksums(source, info.LatestVersion)
+	switch {
+	case errors.Is(err, updater.ErrNoChecksums) && allowUnverified:
+		fmt.Printf("⚠️  %s has no signed checksums. Installing it WITHOUT verification because --allow-unverified was given.\n", info.LatestVersion)
+		fmt.Println("⚠️  A corrupted or tampered download will not be detected.")
+	case errors.Is(err, updater.ErrNoChecksums):
+		fmt.Printf("❌ Cannot verify %s: %v\n", info.LatestVersion, err)
+		fmt.Println("💡 To install it anyway, unverified, run:")
+		fmt.Printf("   glm update --version %s --allow-unverified\n", info.LatestVersion)
+		return err
+	case err != nil:
+		fmt.Printf("❌ Cannot verify this release: %v\n", err)
+		fmt.Println("💡 The update was not installed. Try again later or download manually from:")
+		fmt.Printf("   %s\n", info.ReleaseURL)
+		return err
+	default:
+		if updater.SignedReleases() {
+			fmt.Println("✅ Checksums signature verified")
+		} else {
+			fmt.Println("✅ Checksums downloaded (this build has no release signing key)")
+		}
+		if expectedSHA256, err = checksums.Lookup(binaryName); err != nil {
+			fmt.Printf("❌ %v\n", err)
+			return err
+		}
+	}
+
+	fmt.Printf("📥 Downloading glm %s for %s/%s...\n", info.LatestVersion, osName, arch)
 
 	var lastPercent int
 	progressCallback := func(downloaded, total int64) {
@@ -94,7 +192,7 @@ func runUpdate(checkOnly, force bool) error {
 		}
 	}
 
-	binaryPath, err := updater.DownloadBinary(info.LatestVersion, osName, arch, progressCallback)
+	binaryPath, err := updater.DownloadBinary(source, info.LatestVersion, osName, arch, expectedSHA256, progressCallback)
 	if err != nil {
 		fmt.Printf("\n❌ Failed to download update: %v\n", err)
 		fmt.Println("💡 Try again later or download manually from:")
@@ -102,16 +200,24 @@ func runUpdate(checkOnly, force bool) error {
 		return err
 	}
 
-	fmt.Println("\n✅ Download complete!")
+	if expectedSHA256 != "" {
+		fmt.Println("\n✅ Download complete and checksum verified!")
+	} else {
+		fmt.Println("\n✅ Download complete (not verified)")
+	}
 
 	fmt.Println("🔧 Installing update...")
 
-	if err := updater.VerifyBinary(binaryPath); err != nil {
+	if err := updater.VerifyBinary(binaryPath, expectedSHA256); err != nil {
+		os.Remove(binaryPath)
 		fmt.Printf("❌ Failed to verify downloaded binary: %v\n", err)
 		return err
 	}
 
-	if err := updater.InstallUpdate(binaryPath); err != nil {
+	fmt.Printf("🧪 Checking that glm %s starts...\n", info.LatestVersion)
+
+	if err := updater.InstallUpdate(binaryPath, info.LatestVersion, version); err != nil {
+		os.Remove(binaryPath)
 		fmt.Printf("❌ Failed to install update: %v\n", err)
 		if strings.Contains(err.Error(), "permission denied") {
 			fmt.Println("💡 Try running with sudo:")
@@ -122,10 +228,101 @@ func runUpdate(checkOnly, force bool) error {
 
 	fmt.Printf("✅ Successfully updated to %s!\n\n", info.LatestVersion)
 	fmt.Println("🎉 GLM has been updated! The new version is now active.")
+	fmt.Printf("💡 Version %s was kept in %s; run 'glm update --rollback' to restore it.\n", version, paths.GetVersionsDir())
+	if info.Downgrade {
+		warnEncryptedTokens()
+	}
+
+	return nil
+}
+
+func runListReleases(channel string) error {
+	source, err := releaseSource()
+	if err != nil {
+		return err
+	}
+
+	releases, err := updater.ListReleases(source, channel)
+	if err != nil {
+		fmt.Println("❌ Unable to list releases. Please check your internet connection.")
+		return fmt.Errorf("failed to list releases: %v", err)
+	}
+
+	if len(releases) == 0 {
+		fmt.Printf("No %s releases found.\n", channel)
+		return nil
+	}
+
+	for _, release := range releases {
+		marker := "  "
+		if updater.CompareVersions(version, release.TagName) == 0 {
+			marker = "* "
+		}
+
+		line := fmt.Sprintf("%s%-16s %s", marker, release.TagName, release.Date())
+		if release.Prerelease {
+			line += "  (prerelease)"
+		}
+		fmt.Println(strings.TrimRight(line, " "))
+	}
+
+	if channel != updater.ChannelBeta {
+		fmt.Println("\n💡 Add --channel beta to include prereleases")
+	}
+	return nil
+}
+
+// releaseSource returns the configured release source and says where it
+// points when that is not GitHub.
+func releaseSource() (updater.Source, error) {
+	source, err := updater.ConfiguredSource()
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		return nil, err
+	}
+	if source.Name() != "GitHub" {
+		fmt.Printf("🌐 Release source: %s\n", source.Name())
+	}
+	return source, nil
+}
+
+func runRollback() error {
+	fmt.Printf("📌 Current version: %s\n", version)
+	fmt.Println("⏪ Restoring the previous version...")
+
+	unlock, err := updater.Lock()
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		return err
+	}
+	defer unlock()
+
+	restored, err := updater.Rollback(version)
+	if err != nil {
+		fmt.Printf("❌ Rollback failed: %v\n", err)
+		if strings.Contains(err.Error(), "permission denied") {
+			fmt.Println("💡 Try running with sudo:")
+			fmt.Println("   sudo glm update --rollback")
+		}
+		return err
+	}
 
+	fmt.Printf("✅ Rolled back to %s\n", restored)
+	warnEncryptedTokens()
 	return nil
 }
 
+// warnEncryptedTokens tells users going back to an older glm that it cannot
+// read tokens sealed in the vault.
+func warnEncryptedTokens() {
+	cfg, err := config.Load()
+	if err != nil || cfg.Vault == nil {
+		return
+	}
+	fmt.Println("⚠️  Your stored tokens are encrypted, which versions before 1.2 cannot read.")
+	fmt.Println("💡 With an older version, set ANTHROPIC_AUTH_TOKEN or run 'glm token set' again.")
+}
+
 func showProgress(percent int, downloaded, total int64) {
 	barWidth := 40
 	filled := barWidth * percent / 100
@@ -0,0 +1,116 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const probeTimeout = 15 * time.Second
+
+type ProbeResult struct {
+	StatusCode int
+	Latency    time.Duration
+	Message    string
+	JSON       bool
+	Err        error
+}
+
+type Verdict string
+
+const (
+	VerdictValid         Verdict = "valid"
+	VerdictUnauthorized  Verdict = "unauthorized"
+	VerdictWrongEndpoint Verdict = "wrong-endpoint"
+	VerdictRateLimited   Verdict = "rate-limited"
+	VerdictNetworkError  Verdict = "network-error"
+	VerdictUnexpected    Verdict = "unexpected"
+)
+
+func (r *ProbeResult) OK() bool {
+	return r.Err == nil && r.StatusCode == http.StatusOK
+}
+
+// Verdict classifies the probe. A rate-limited answer still means the
+// endpoint recognised the token.
+func (r *ProbeResult) Verdict() Verdict {
+	switch {
+	case r.Err != nil:
+		return VerdictNetworkError
+	case r.StatusCode == http.StatusUnauthorized || r.StatusCode == http.StatusForbidden:
+		return VerdictUnauthorized
+	case r.StatusCode == http.StatusTooManyRequests:
+		return VerdictRateLimited
+	case r.StatusCode == http.StatusNotFound || r.StatusCode == http.StatusMethodNotAllowed:
+		return VerdictWrongEndpoint
+	case r.StatusCode >= 200 && r.StatusCode < 300 && !r.JSON:
+		return VerdictWrongEndpoint
+	case r.StatusCode >= 200 && r.StatusCode < 300:
+		return VerdictValid
+	default:
+		return VerdictUnexpected
+	}
+}
+
+// Probe sends the smallest possible authenticated request (a one-token
+// message) to confirm that baseURL is reachable and accepts token.
+func Probe(ctx context.Context, baseURL, token, model string) *ProbeResult {
+	ctx, cancel := context.WithTimeout(ctx, probeTimeout)
+	defer cancel()
+
+	payload, _ := json.Marshal(map[string]any{
+		"model":      model,
+		"max_tokens": 1,
+		"messages":   []map[string]string{{"role": "user", "content": "ping"}},
+	})
+
+	endpoint := strings.TrimRight(baseURL, "/") + "/v1/messages"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return &ProbeResult{Err: fmt.Errorf("invalid base URL: %v", err)}
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("anthropic-version", "2023-06-01")
+
+	start := time.Now()
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return &ProbeResult{Err: err, Latency: time.Since(start)}
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+
+	return &ProbeResult{
+		StatusCode: resp.StatusCode,
+		Latency:    time.Since(start),
+		Message:    errorMessage(body),
+		JSON:       json.Valid(body),
+	}
+}
+
+func errorMessage(body []byte) string {
+	var payload struct {
+		Error struct {
+			Message string `json:"message"`
+		} `json:"error"`
+		Message string `json:"message"`
+		Msg     string `json:"msg"`
+	}
+	if json.Unmarshal(body, &payload) != nil {
+		return ""
+	}
+
+	for _, message := range []string{payload.Error.Message, payload.Message, payload.Msg} {
+		if message != "" {
+			return message
+		}
+	}
+	return ""
+}
@@ -0,0 +1,80 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestProbeVerdict(t *testing.T) {
+	tests := []struct {
+		name        string
+		status      int
+		contentType string
+		body        string
+		want        Verdict
+	}{
+		{"valid", http.StatusOK, "application/json", `{"id":"msg_1","type":"message"}`, VerdictValid},
+		{"html portal", http.StatusOK, "text/html", `<html><body>Login</body></html>`, VerdictWrongEndpoint},
+		{"unauthorized", http.StatusUnauthorized, "application/json", `{"error":{"message":"invalid api key"}}`, VerdictUnauthorized},
+		{"forbidden", http.StatusForbidden, "application/json", `{"error":{"message":"forbidden"}}`, VerdictUnauthorized},
+		{"not found", http.StatusNotFound, "text/html", `<html>404</html>`, VerdictWrongEndpoint},
+		{"rate limited", http.StatusTooManyRequests, "application/json", `{"error":{"message":"slow down"}}`, VerdictRateLimited},
+		{"server error", http.StatusInternalServerError, "application/json", `{"msg":"boom"}`, VerdictUnexpected},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/v1/messages" {
+					t.Errorf("unexpected path %s", r.URL.Path)
+				}
+				if got := r.Header.Get("Authorization"); got != "Bearer test-token" {
+					t.Errorf("Authorization = %q", got)
+				}
+				w.Header().Set("Content-Type", tt.contentType)
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			result := Probe(context.Background(), server.URL, "test-token", "glm-4.6")
+			if got := result.Verdict(); got != tt.want {
+				t.Errorf("Verdict() = %s, want %s (status %d)", got, tt.want, result.StatusCode)
+			}
+		})
+	}
+}
+
+func TestProbeVerdictNetworkError(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	url := server.URL
+	server.Close()
+
+	result := Probe(context.Background(), url, "test-token", "glm-4.6")
+	if result.Err == nil {
+		t.Fatal("expected an error from a closed server")
+	}
+	if got := result.Verdict(); got != VerdictNetworkError {
+		t.Errorf("Verdict() = %s, want %s", got, VerdictNetworkError)
+	}
+}
+
+func TestProbeErrorMessage(t *testing.T) {
+	tests := []struct {
+		body string
+		want string
+	}{
+		{`{"error":{"message":"invalid api key"}}`, "invalid api key"},
+		{`{"message":"top level"}`, "top level"},
+		{`{"msg":"bigmodel style"}`, "bigmodel style"},
+		{`<html></html>`, ""},
+	}
+
+	for _, tt := range tests {
+		if got := errorMessage([]byte(tt.body)); got != tt.want {
+			t.Errorf("errorMessage(%s) = %q, want %q", tt.body, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	AuthModeKey = "key"
+	AuthModeJWT = "jwt"
+
+	DefaultJWTTTL = 30 * time.Minute
+	minJWTTTL     = time.Minute
+	maxJWTTTL     = 24 * time.Hour
+)
+
+func ValidateAuthMode(mode string) error {
+	if mode != AuthModeKey && mode != AuthModeJWT {
+		return fmt.Errorf("invalid auth mode %q: expected %q or %q", mode, AuthModeKey, AuthModeJWT)
+	}
+	return nil
+}
+
+func ParseJWTTTL(value string) (time.Duration, error) {
+	ttl, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid JWT TTL %q: use a duration such as 30m or 2h", value)
+	}
+	if ttl < minJWTTTL || ttl > maxJWTTTL {
+		return 0, fmt.Errorf("invalid JWT TTL %q: must be between %s and %s", value, minJWTTTL, maxJWTTTL)
+	}
+	return ttl, nil
+}
+
+// AuthModeFor returns the auth mode for p, falling back to the global
+// auth_mode and then to sending the key as-is.
+func (c *Config) AuthModeFor(p *Profile) string {
+	switch {
+	case p.AuthMode != "":
+		return p.AuthMode
+	case c.AuthMode != "":
+		return c.AuthMode
+	default:
+		return AuthModeKey
+	}
+}
+
+func (c *Config) JWTLifetime() time.Duration {
+	if ttl, err := ParseJWTTTL(c.JWTTTL); err == nil {
+		return ttl
+	}
+	return DefaultJWTTTL
+}
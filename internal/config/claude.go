@@ -0,0 +1,226 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/xqsit94/glm/pkg/paths"
+)
+
+var ManagedEnvKeys = []string{"ANTHROPIC_BASE_URL", "ANTHROPIC_AUTH_TOKEN", "ANTHROPIC_MODEL"}
+
+type ClaudeSettings struct {
+	root     *jsonObject
+	original []byte
+	changed  bool
+}
+
+func LoadClaudeSettings() (*ClaudeSettings, error) {
+	settingsPath := paths.GetClaudeSettingsPath()
+
+	data, err := os.ReadFile(settingsPath)
+	if os.IsNotExist(err) {
+		return &ClaudeSettings{root: newJSONObject()}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read settings file: %v", err)
+	}
+
+	root, err := parseJSONObject(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse settings file: %v", err)
+	}
+
+	return &ClaudeSettings{root: root, original: data}, nil
+}
+
+func (s *ClaudeSettings) Exists() bool {
+	return s.original != nil
+}
+
+func (s *ClaudeSettings) IsEmpty() bool {
+	return s.root.len() == 0
+}
+
+func (s *ClaudeSettings) envObject() (*jsonObject, error) {
+	raw, ok := s.root.get("env")
+	if !ok {
+		return newJSONObject(), nil
+	}
+
+	env, err := parseJSONObject(raw)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse env block in settings file: %v", err)
+	}
+	return env, nil
+}
+
+func (s *ClaudeSettings) Env(key string) (string, bool) {
+	env, err := s.envObject()
+	if err != nil {
+		return "", false
+	}
+
+	raw, ok := env.get(key)
+	if !ok {
+		return "", false
+	}
+
+	var value string
+	if json.Unmarshal(raw, &value) != nil {
+		return "", false
+	}
+	return value, true
+}
+
+func (s *ClaudeSettings) SetEnv(key, value string) error {
+	env, err := s.envObject()
+	if err != nil {
+		return err
+	}
+
+	if current, ok := s.Env(key); ok && current == value {
+		return nil
+	}
+
+	raw, err := marshalJSONString(value)
+	if err != nil {
+		return err
+	}
+	env.set(key, raw)
+
+	return s.storeEnv(env)
+}
+
+func (s *ClaudeSettings) UnsetEnv(key string) (bool, error) {
+	env, err := s.envObject()
+	if err != nil {
+		return false, err
+	}
+
+	if !env.delete(key) {
+		return false, nil
+	}
+
+	return true, s.storeEnv(env)
+}
+
+func (s *ClaudeSettings) storeEnv(env *jsonObject) error {
+	s.changed = true
+
+	if env.len() == 0 {
+		s.root.delete("env")
+		return nil
+	}
+
+	data, err := env.marshal()
+	if err != nil {
+		return fmt.Errorf("failed to marshal env block: %v", err)
+	}
+	s.root.set("env", data)
+	return nil
+}
+
+func (s *ClaudeSettings) Marshal() ([]byte, error) {
+	data, err := s.root.marshal()
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal settings: %v", err)
+	}
+	return append(data, '\n'), nil
+}
+
+func (s *ClaudeSettings) Original() []byte {
+	return s.original
+}
+
+// SaveClaudeSettings writes the settings back, first copying the current
+// file to a timestamped backup. It returns the backup path, or "" when
+// nothing was backed up because the file did not exist or did not change.
+func SaveClaudeSettings(settings *ClaudeSettings) (string, error) {
+	data, err := settings.Marshal()
+	if err != nil {
+		return "", err
+	}
+
+	if settings.Exists() && !settings.changed {
+		return "", nil
+	}
+
+	settingsPath := paths.GetClaudeSettingsPath()
+	if err := os.MkdirAll(filepath.Dir(settingsPath), 0755); err != nil {
+		return "", fmt.Errorf("failed to create directory: %v", err)
+	}
+
+	mode := os.FileMode(0644)
+	var backupPath string
+	if settings.Exists() {
+		if info, err := os.Stat(settingsPath); err == nil {
+			mode = info.Mode().Perm()
+		}
+
+		backupPath, err = writeBackup(settingsPath, settings.original, mode)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	tmpPath := settingsPath + ".glm-tmp"
+	if err := os.WriteFile(tmpPath, data, mode); err != nil {
+		return "", fmt.Errorf("failed to write settings file: %v", err)
+	}
+	if err := os.Rename(tmpPath, settingsPath); err != nil {
+		os.Remove(tmpPath)
+		return "", fmt.Errorf("failed to write settings file: %v", err)
+	}
+
+	settings.original = data
+	settings.changed = false
+	return backupPath, nil
+}
+
+func RemoveClaudeSettings(settings *ClaudeSettings) (string, error) {
+	if !settings.Exists() {
+		return "", nil
+	}
+
+	settingsPath := paths.GetClaudeSettingsPath()
+	backupPath, err := writeBackup(settingsPath, settings.original, 0600)
+	if err != nil {
+		return "", err
+	}
+	if err := os.Remove(settingsPath); err != nil {
+		return "", fmt.Errorf("failed to remove settings file: %v", err)
+	}
+
+	settings.original = nil
+	return backupPath, nil
+}
+
+func writeBackup(path string, data []byte, mode os.FileMode) (string, error) {
+	base := fmt.Sprintf("%s.glm-backup-%s", path, time.Now().Format("20060102-150405"))
+
+	backupPath := base
+	for i := 1; ; i++ {
+		f, err := os.OpenFile(backupPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, mode)
+		if os.IsExist(err) {
+			backupPath = fmt.Sprintf("%s-%d", base, i)
+			continue
+		}
+		if err != nil {
+			return "", fmt.Errorf("failed to write settings backup: %v", err)
+		}
+
+		_, err = f.Write(data)
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			os.Remove(backupPath)
+			return "", fmt.Errorf("failed to write settings backup: %v", err)
+		}
+		return backupPath, nil
+	}
+}
@@ -8,20 +8,42 @@ import (
 	"github.com/xqsit94/glm/pkg/paths"
 )
 
+const (
+	BuiltinModel     = "glm-4.6"
+	BuiltinFastModel = "glm-4.5-air"
+)
+
 type Config struct {
-	AnthropicAuthToken string `json:"anthropic_auth_token"`
-	DefaultModel       string `json:"default_model,omitempty"`
+	AnthropicAuthToken string             `json:"anthropic_auth_token,omitempty"`
+	Tokens             map[string]string  `json:"tokens,omitempty"`
+	ActiveToken        string             `json:"active_token,omitempty"`
+	Vault              *Vault             `json:"vault,omitempty"`
+	TokenCommand       []string           `json:"token_command,omitempty"`
+	TokenFile          string             `json:"token_file,omitempty"`
+	AuthMode           string             `json:"auth_mode,omitempty"`
+	JWTTTL             string             `json:"jwt_ttl,omitempty"`
+	DefaultModel       string             `json:"default_model,omitempty"`
+	Models             ModelMap           `json:"models,omitzero"`
+	ActiveProfile      string             `json:"active_profile,omitempty"`
+	Profiles           map[string]Profile `json:"profiles,omitempty"`
+	EnvAllow           []string           `json:"env_allow,omitempty"`
+	EnvDeny            []string           `json:"env_deny,omitempty"`
+	UpdateMirror       string             `json:"update_mirror,omitempty"`
+	MigrationChecked   string             `json:"migration_checked,omitempty"`
 }
 
-type ClaudeSettings struct {
-	Env struct {
-		AnthropicBaseURL   string `json:"ANTHROPIC_BASE_URL"`
-		AnthropicAuthToken string `json:"ANTHROPIC_AUTH_TOKEN"`
-		AnthropicModel     string `json:"ANTHROPIC_MODEL"`
-	} `json:"env"`
+type ModelMap struct {
+	SmallFast string `json:"small_fast,omitempty" toml:"small_fast"`
+	Opus      string `json:"opus,omitempty" toml:"opus"`
+	Sonnet    string `json:"sonnet,omitempty" toml:"sonnet"`
+	Haiku     string `json:"haiku,omitempty" toml:"haiku"`
 }
 
 func Load() (*Config, error) {
+	if err := migrateLegacyConfig(); err != nil {
+		return nil, err
+	}
+
 	configPath := paths.GetConfigPath()
 
 	if _, err := os.Stat(configPath); os.IsNotExist(err) {
@@ -37,6 +59,7 @@ func Load() (*Config, error) {
 	if err := json.Unmarshal(data, &config); err != nil {
 		return nil, fmt.Errorf("failed to parse config file: %v", err)
 	}
+	config.upgradeLegacyToken()
 
 	return &config, nil
 }
@@ -48,9 +71,11 @@ func Save(config *Config) error {
 		return fmt.Errorf("failed to create config directory: %v", err)
 	}
 
-	data, err := json.MarshalIndent(config, "", "  ")
+	saved := *config
+	saved.AnthropicAuthToken = config.legacyToken()
+	data, err := Marshal(&saved)
 	if err != nil {
-		return fmt.Errorf("failed to marshal config: %v", err)
+		return err
 	}
 
 	configPath := paths.GetConfigPath()
@@ -61,36 +86,10 @@ func Save(config *Config) error {
 	return nil
 }
 
-func LoadClaudeSettings() (*ClaudeSettings, error) {
-	settingsPath := paths.GetClaudeSettingsPath()
-
-	if _, err := os.Stat(settingsPath); os.IsNotExist(err) {
-		return nil, fmt.Errorf("GLM is not enabled. Run 'glm enable' first")
-	}
-
-	data, err := os.ReadFile(settingsPath)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read settings file: %v", err)
-	}
-
-	var settings ClaudeSettings
-	if err := json.Unmarshal(data, &settings); err != nil {
-		return nil, fmt.Errorf("failed to parse settings file: %v", err)
-	}
-
-	return &settings, nil
-}
-
-func SaveClaudeSettings(settings *ClaudeSettings) error {
-	data, err := json.MarshalIndent(settings, "", "  ")
+func Marshal(config *Config) ([]byte, error) {
+	data, err := json.MarshalIndent(config, "", "  ")
 	if err != nil {
-		return fmt.Errorf("failed to marshal settings: %v", err)
-	}
-
-	settingsPath := paths.GetClaudeSettingsPath()
-	if err := os.WriteFile(settingsPath, data, 0644); err != nil {
-		return fmt.Errorf("failed to write settings file: %v", err)
+		return nil, fmt.Errorf("failed to marshal config: %v", err)
 	}
-
-	return nil
+	return data, nil
 }
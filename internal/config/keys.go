@@ -0,0 +1,286 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var (
+	modelPattern      = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._:/@+-]*$`)
+	envPatternPattern = regexp.MustCompile(`^[A-Za-z0-9_*?]+$`)
+)
+
+type key struct {
+	secret bool
+	get    func(c *Config) string
+	set    func(c *Config, value string) error
+}
+
+var keys = map[string]key{
+	"anthropic_auth_token": {
+		secret: true,
+		get: func(c *Config) string {
+			value, _ := c.Token(c.ActiveTokenName())
+			return value
+		},
+		set: func(c *Config, value string) error {
+			if c.Vault != nil && value != "" && !IsEncryptedToken(value) {
+				return fmt.Errorf("tokens are encrypted. Use 'glm token set' instead")
+			}
+			if value == "" {
+				c.RemoveToken(c.ActiveTokenName())
+			} else {
+				c.SetToken(c.ActiveTokenName(), value)
+			}
+			return nil
+		},
+	},
+	"active_token": {
+		get: func(c *Config) string { return c.ActiveToken },
+		set: func(c *Config, value string) error {
+			if value != "" {
+				if _, ok := c.Token(value); !ok {
+					return fmt.Errorf("token %q not found. Run 'glm token list' to see stored tokens", value)
+				}
+			}
+			c.ActiveToken = value
+			return nil
+		},
+	},
+	"token_command": {
+		get: func(c *Config) string { return strings.Join(c.TokenCommand, " ") },
+		set: func(c *Config, value string) error {
+			if value != "" && c.TokenFile != "" {
+				return fmt.Errorf("token_file is already set; use either token_command or token_file")
+			}
+			c.TokenCommand = strings.Fields(value)
+			return nil
+		},
+	},
+	"token_file": {
+		get: func(c *Config) string { return c.TokenFile },
+		set: func(c *Config, value string) error {
+			if value != "" && len(c.TokenCommand) > 0 {
+				return fmt.Errorf("token_command is already set; use either token_command or token_file")
+			}
+			c.TokenFile = value
+			return nil
+		},
+	},
+	"auth_mode": {
+		get: func(c *Config) string { return c.AuthMode },
+		set: func(c *Config, value string) error {
+			if value != "" {
+				if err := ValidateAuthMode(value); err != nil {
+					return err
+				}
+			}
+			c.AuthMode = value
+			return nil
+		},
+	},
+	"jwt_ttl": {
+		get: func(c *Config) string { return c.JWTTTL },
+		set: func(c *Config, value string) error {
+			if value != "" {
+				if _, err := ParseJWTTTL(value); err != nil {
+					return err
+				}
+			}
+			c.JWTTTL = value
+			return nil
+		},
+	},
+	"update_mirror": {
+		get: func(c *Config) string { return c.UpdateMirror },
+		set: func(c *Config, value string) error {
+			if value != "" {
+				if err := ValidateUpdateMirror(value); err != nil {
+					return err
+				}
+			}
+			c.UpdateMirror = value
+			return nil
+		},
+	},
+	"default_model":     modelKey(func(c *Config) *string { return &c.DefaultModel }),
+	"models.small_fast": modelKey(func(c *Config) *string { return &c.Models.SmallFast }),
+	"models.opus":       modelKey(func(c *Config) *string { return &c.Models.Opus }),
+	"models.sonnet":     modelKey(func(c *Config) *string { return &c.Models.Sonnet }),
+	"models.haiku":      modelKey(func(c *Config) *string { return &c.Models.Haiku }),
+	"env_allow":         listKey(func(c *Config) *[]string { return &c.EnvAllow }),
+	"env_deny":          listKey(func(c *Config) *[]string { return &c.EnvDeny }),
+	"active_profile": {
+		get: func(c *Config) string { return c.ActiveProfile },
+		set: func(c *Config, value string) error {
+			if value != "" {
+				if _, err := c.Profile(value); err != nil {
+					return err
+				}
+			}
+			c.ActiveProfile = value
+			return nil
+		},
+	},
+}
+
+func modelKey(field func(c *Config) *string) key {
+	return key{
+		get: func(c *Config) string { return *field(c) },
+		set: func(c *Config, value string) error {
+			if value != "" {
+				if err := ValidateModel(value); err != nil {
+					return err
+				}
+			}
+			*field(c) = value
+			return nil
+		},
+	}
+}
+
+func listKey(field func(c *Config) *[]string) key {
+	return key{
+		get: func(c *Config) string { return strings.Join(*field(c), ",") },
+		set: func(c *Config, value string) error {
+			var items []string
+			for _, item := range strings.Split(value, ",") {
+				item = strings.TrimSpace(item)
+				if item == "" {
+					continue
+				}
+				if _, err := path.Match(item, ""); err != nil || !envPatternPattern.MatchString(item) {
+					return fmt.Errorf("invalid environment variable pattern %q", item)
+				}
+				items = append(items, item)
+			}
+			*field(c) = items
+			return nil
+		},
+	}
+}
+
+func Keys() []string {
+	names := make([]string, 0, len(keys))
+	for name := range keys {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func IsSecretKey(name string) bool {
+	return keys[name].secret
+}
+
+func lookupKey(name string) (key, error) {
+	k, ok := keys[name]
+	if !ok {
+		return key{}, fmt.Errorf("unknown config key %q (valid keys: %s)", name, strings.Join(Keys(), ", "))
+	}
+	return k, nil
+}
+
+// ValidateModel accepts any model ID a gateway might use (glm-4.6,
+// claude-sonnet-4-5, vendor/model:tag) but rejects spaces, quotes and
+// other characters that cannot be part of one.
+func ValidateModel(model string) error {
+	if !modelPattern.MatchString(model) {
+		return fmt.Errorf("invalid model name %q: use letters, digits and '.', '_', ':', '/', '@', '+' or '-'", model)
+	}
+	return nil
+}
+
+func (c *Config) Get(name string) (string, error) {
+	k, err := lookupKey(name)
+	if err != nil {
+		return "", err
+	}
+	return k.get(c), nil
+}
+
+func (c *Config) Set(name, value string) error {
+	k, err := lookupKey(name)
+	if err != nil {
+		return err
+	}
+	if value == "" {
+		return fmt.Errorf("value for %s cannot be empty. Use 'glm config unset %s' instead", name, name)
+	}
+	return k.set(c, value)
+}
+
+func (c *Config) Unset(name string) error {
+	k, err := lookupKey(name)
+	if err != nil {
+		return err
+	}
+	return k.set(c, "")
+}
+
+func (c *Config) Validate() error {
+	for _, name := range Keys() {
+		value := keys[name].get(c)
+		if value == "" {
+			continue
+		}
+		scratch := *c
+		if err := keys[name].set(&scratch, value); err != nil {
+			return fmt.Errorf("%s: %v", name, err)
+		}
+	}
+
+	for name, p := range c.Profiles {
+		if err := ValidateProfile(name, &p); err != nil {
+			return fmt.Errorf("profiles.%s: %v", name, err)
+		}
+		if p.TokenName != "" {
+			if _, ok := c.Token(p.TokenName); !ok {
+				return fmt.Errorf("profiles.%s: token %q not found", name, p.TokenName)
+			}
+		}
+	}
+
+	for name, value := range c.Tokens {
+		if err := ValidateTokenName(name); err != nil {
+			return fmt.Errorf("tokens: %v", err)
+		}
+		if c.Vault != nil && !IsEncryptedToken(value) {
+			return fmt.Errorf("tokens.%s is not encrypted. Use 'glm token set --name %s' to store it", name, name)
+		}
+	}
+
+	return nil
+}
+
+func Parse(data []byte) (*Config, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+
+	var config Config
+	if err := dec.Decode(&config); err != nil {
+		return nil, err
+	}
+	config.upgradeLegacyToken()
+
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &config, nil
+}
+
+func MaskSecret(secret string) string {
+	if IsEncryptedToken(secret) {
+		return "(encrypted)"
+	}
+	if len(secret) > 8 {
+		return secret[:4] + strings.Repeat("*", len(secret)-8) + secret[len(secret)-4:]
+	}
+	return "****"
+}
@@ -0,0 +1,57 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/xqsit94/glm/pkg/paths"
+)
+
+var migrateOnce sync.Once
+
+func migrateLegacyConfig() error {
+	var migrateErr error
+
+	migrateOnce.Do(func() {
+		// GLM_HOME is an explicit, self-contained location (often a
+		// throwaway test directory), so nothing is copied into it.
+		if os.Getenv("GLM_HOME") != "" {
+			return
+		}
+
+		legacyPath := paths.GetLegacyConfigPath()
+		configPath := paths.GetConfigPath()
+		if legacyPath == configPath {
+			return
+		}
+		if _, err := os.Stat(legacyPath); err != nil {
+			return
+		}
+		if _, err := os.Stat(configPath); err == nil {
+			return
+		}
+
+		if err := os.MkdirAll(filepath.Dir(configPath), 0700); err != nil {
+			migrateErr = fmt.Errorf("failed to create config directory: %v", err)
+			return
+		}
+
+		data, err := os.ReadFile(legacyPath)
+		if err != nil {
+			migrateErr = fmt.Errorf("failed to read legacy config file: %v", err)
+			return
+		}
+		if err := os.WriteFile(configPath, data, 0600); err != nil {
+			migrateErr = fmt.Errorf("failed to write config file: %v", err)
+			return
+		}
+
+		// The legacy file stays so that a binary restored by
+		// 'glm update --rollback' or --version still finds its config.
+		fmt.Fprintf(os.Stderr, "📦 Copied config from %s to %s (the old file is kept for older glm versions)\n", legacyPath, configPath)
+	})
+
+	return migrateErr
+}
@@ -0,0 +1,134 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const DefaultProfile = "bigmodel"
+
+type Profile struct {
+	Name         string            `json:"-"`
+	BaseURL      string            `json:"base_url"`
+	TokenName    string            `json:"token_name,omitempty"`
+	TokenEnv     string            `json:"token_env,omitempty"`
+	AuthMode     string            `json:"auth_mode,omitempty"`
+	DefaultModel string            `json:"default_model,omitempty"`
+	Env          map[string]string `json:"env,omitempty"`
+}
+
+var builtinProfiles = map[string]Profile{
+	"bigmodel": {BaseURL: "https://open.bigmodel.cn/api/anthropic"},
+	"zai":      {BaseURL: "https://api.z.ai/api/anthropic"},
+}
+
+var (
+	profileNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]*$`)
+	envNamePattern     = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+)
+
+func IsBuiltinProfile(name string) bool {
+	_, ok := builtinProfiles[name]
+	return ok
+}
+
+func (c *Config) Profile(name string) (*Profile, error) {
+	if name == "" {
+		name = c.ActiveProfile
+	}
+	if name == "" {
+		name = DefaultProfile
+	}
+
+	if p, ok := c.Profiles[name]; ok {
+		p.Name = name
+		return &p, nil
+	}
+	if p, ok := builtinProfiles[name]; ok {
+		p.Name = name
+		return &p, nil
+	}
+
+	return nil, fmt.Errorf("profile %q not found. Run 'glm profile list' to see available profiles", name)
+}
+
+func (c *Config) ProfileNames() []string {
+	seen := make(map[string]bool)
+	var names []string
+	for name := range builtinProfiles {
+		seen[name] = true
+		names = append(names, name)
+	}
+	for name := range c.Profiles {
+		if !seen[name] {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func ValidateProfile(name string, p *Profile) error {
+	if !profileNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid profile name %q: use letters, digits, '.', '_' or '-'", name)
+	}
+
+	u, err := url.Parse(p.BaseURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid base URL %q: must be an http or https URL", p.BaseURL)
+	}
+
+	if p.TokenName != "" && p.TokenEnv != "" {
+		return fmt.Errorf("a profile can use either a token name or a token environment variable, not both")
+	}
+
+	if p.TokenName != "" {
+		if err := ValidateTokenName(p.TokenName); err != nil {
+			return err
+		}
+	}
+
+	if p.TokenEnv != "" && !envNamePattern.MatchString(p.TokenEnv) {
+		return fmt.Errorf("invalid token environment variable name %q", p.TokenEnv)
+	}
+
+	if p.AuthMode != "" {
+		if err := ValidateAuthMode(p.AuthMode); err != nil {
+			return err
+		}
+	}
+
+	if p.DefaultModel != "" {
+		if err := ValidateModel(p.DefaultModel); err != nil {
+			return err
+		}
+	}
+
+	for key := range p.Env {
+		if !envNamePattern.MatchString(key) {
+			return fmt.Errorf("invalid environment variable name %q", key)
+		}
+	}
+
+	return nil
+}
+
+func ParseEnvPairs(pairs []string) (map[string]string, error) {
+	if len(pairs) == 0 {
+		return nil, nil
+	}
+
+	env := make(map[string]string, len(pairs))
+	for _, pair := range pairs {
+		key, value, ok := strings.Cut(pair, "=")
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid environment variable %q: expected KEY=VALUE", pair)
+		}
+		env[key] = value
+	}
+
+	return env, nil
+}
@@ -0,0 +1,180 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+var ProjectFileNames = []string{".glm.json", ".glm.toml"}
+
+var forbiddenProjectKeys = []string{"anthropic_auth_token", "token", "tokens", "auth_token", "api_key"}
+
+var forbiddenProjectEnv = []string{"ANTHROPIC_AUTH_TOKEN", "ANTHROPIC_API_KEY"}
+
+// unsafeProjectEnv can route or expose the session's token, so a project
+// file from a cloned repository may not set it.
+var unsafeProjectEnv = []string{
+	"HTTP_PROXY", "HTTPS_PROXY", "ALL_PROXY", "NO_PROXY",
+	"NODE_OPTIONS", "NODE_TLS_REJECT_UNAUTHORIZED", "NODE_EXTRA_CA_CERTS",
+	"SSL_CERT_FILE", "SSL_CERT_DIR",
+}
+
+type ProjectConfig struct {
+	Path       string            `json:"-" toml:"-"`
+	Model      string            `json:"model,omitempty" toml:"model"`
+	Profile    string            `json:"profile,omitempty" toml:"profile"`
+	Models     ModelMap          `json:"models,omitzero" toml:"models"`
+	Env        map[string]string `json:"env,omitempty" toml:"env"`
+	ClaudeArgs []string          `json:"claude_args,omitempty" toml:"claude_args"`
+}
+
+// FindProjectFile looks for a project file from the working directory up to
+// the enclosing git repository, stopping at the home directory.
+func FindProjectFile() (string, error) {
+	dir, err := os.Getwd()
+	if err != nil {
+		return "", fmt.Errorf("failed to get working directory: %v", err)
+	}
+	home, _ := os.UserHomeDir()
+
+	for {
+		var found []string
+		for _, name := range ProjectFileNames {
+			candidate := filepath.Join(dir, name)
+			if _, err := os.Stat(candidate); err == nil {
+				found = append(found, candidate)
+			}
+		}
+		if len(found) > 1 {
+			return "", fmt.Errorf("found both %s in %s; keep only one", strings.Join(ProjectFileNames, " and "), dir)
+		}
+		if len(found) == 1 {
+			return found[0], nil
+		}
+
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+			return "", nil
+		}
+		if home != "" && dir == filepath.Clean(home) {
+			return "", nil
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", nil
+		}
+		dir = parent
+	}
+}
+
+func LoadProject() (*ProjectConfig, error) {
+	path, err := FindProjectFile()
+	if err != nil || path == "" {
+		return &ProjectConfig{}, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read project config %s: %v", path, err)
+	}
+
+	project, err := parseProject(path, data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid project config %s: %v", path, err)
+	}
+	project.Path = path
+
+	return project, nil
+}
+
+func parseProject(path string, data []byte) (*ProjectConfig, error) {
+	var raw map[string]any
+	var project ProjectConfig
+
+	if filepath.Ext(path) == ".toml" {
+		if _, err := toml.Decode(string(data), &raw); err != nil {
+			return nil, err
+		}
+		if err := rejectProjectTokens(raw); err != nil {
+			return nil, err
+		}
+
+		meta, err := toml.Decode(string(data), &project)
+		if err != nil {
+			return nil, err
+		}
+		if undecoded := meta.Undecoded(); len(undecoded) > 0 {
+			return nil, fmt.Errorf("unknown field %q", undecoded[0].String())
+		}
+	} else {
+		if err := json.Unmarshal(data, &raw); err != nil {
+			return nil, err
+		}
+		if err := rejectProjectTokens(raw); err != nil {
+			return nil, err
+		}
+
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&project); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := project.validate(); err != nil {
+		return nil, err
+	}
+
+	return &project, nil
+}
+
+func rejectProjectTokens(raw map[string]any) error {
+	for key := range raw {
+		for _, forbidden := range forbiddenProjectKeys {
+			if strings.EqualFold(key, forbidden) {
+				return fmt.Errorf("project config must not contain a token (found %q). Use 'glm token set' instead", key)
+			}
+		}
+	}
+
+	if env, ok := raw["env"].(map[string]any); ok {
+		for key := range env {
+			for _, forbidden := range forbiddenProjectEnv {
+				if strings.EqualFold(key, forbidden) {
+					return fmt.Errorf("project config must not contain a token (found env %q). Use 'glm token set' instead", key)
+				}
+			}
+			for _, unsafe := range unsafeProjectEnv {
+				if strings.EqualFold(key, unsafe) {
+					return fmt.Errorf("project config must not set env %q: proxy, TLS and Node options could expose your token. Set it in your shell instead", key)
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+func (p *ProjectConfig) validate() error {
+	for _, model := range []string{p.Model, p.Models.SmallFast, p.Models.Opus, p.Models.Sonnet, p.Models.Haiku} {
+		if model != "" {
+			if err := ValidateModel(model); err != nil {
+				return err
+			}
+		}
+	}
+
+	for key := range p.Env {
+		if !envNamePattern.MatchString(key) {
+			return fmt.Errorf("invalid environment variable name %q", key)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,62 @@
+package config
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/xqsit94/glm/pkg/paths"
+)
+
+type Setting struct {
+	Key    string
+	Value  string
+	Origin string
+	Secret bool
+}
+
+func EffectiveSettings(cfg *Config, project *ProjectConfig) []Setting {
+	values := make(map[string]Setting)
+
+	for _, name := range Keys() {
+		if value := keys[name].get(cfg); value != "" {
+			values[name] = Setting{Key: name, Value: value, Origin: paths.GetConfigPath(), Secret: keys[name].secret}
+		}
+	}
+	for _, name := range cfg.TokenNames() {
+		key := "tokens." + name
+		value, _ := cfg.Token(name)
+		values[key] = Setting{Key: key, Value: value, Origin: paths.GetConfigPath(), Secret: true}
+	}
+	for name, p := range cfg.Profiles {
+		key := "profiles." + name + ".base_url"
+		values[key] = Setting{Key: key, Value: p.BaseURL, Origin: paths.GetConfigPath()}
+	}
+
+	overrides := map[string]string{
+		"default_model":     project.Model,
+		"active_profile":    project.Profile,
+		"models.small_fast": project.Models.SmallFast,
+		"models.opus":       project.Models.Opus,
+		"models.sonnet":     project.Models.Sonnet,
+		"models.haiku":      project.Models.Haiku,
+	}
+	for key, value := range project.Env {
+		overrides["env."+key] = value
+	}
+	if len(project.ClaudeArgs) > 0 {
+		overrides["claude_args"] = strings.Join(project.ClaudeArgs, " ")
+	}
+	for key, value := range overrides {
+		if value != "" {
+			values[key] = Setting{Key: key, Value: value, Origin: project.Path}
+		}
+	}
+
+	settings := make([]Setting, 0, len(values))
+	for _, setting := range values {
+		settings = append(settings, setting)
+	}
+	sort.Slice(settings, func(i, j int) bool { return settings[i].Key < settings[j].Key })
+
+	return settings
+}
@@ -0,0 +1,103 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const (
+	DefaultTokenName     = "default"
+	EncryptedTokenPrefix = "glm-vault:v1:"
+)
+
+// Vault holds the key derivation parameters for encrypted tokens. When it is
+// set, every value in Tokens is sealed with the derived key.
+type Vault struct {
+	KDF        string `json:"kdf"`
+	Iterations int    `json:"iterations"`
+	Salt       string `json:"salt"`
+	Check      string `json:"check"`
+}
+
+func IsEncryptedToken(value string) bool {
+	return strings.HasPrefix(value, EncryptedTokenPrefix)
+}
+
+// upgradeLegacyToken moves the single pre-1.2 anthropic_auth_token into the
+// named token store. Once there are named tokens the field only mirrors the
+// active one for older versions (see legacyToken), so it is not imported.
+func (c *Config) upgradeLegacyToken() {
+	if c.AnthropicAuthToken == "" {
+		return
+	}
+
+	if len(c.Tokens) == 0 {
+		c.SetToken(DefaultTokenName, c.AnthropicAuthToken)
+		if c.ActiveToken == "" {
+			c.ActiveToken = DefaultTokenName
+		}
+	}
+	c.AnthropicAuthToken = ""
+}
+
+// legacyToken is the value written to anthropic_auth_token so that a pre-1.2
+// glm, restored by a rollback or downgrade, still finds a token. Encrypted
+// tokens cannot be read by those versions and are not mirrored.
+func (c *Config) legacyToken() string {
+	value, ok := c.Token(c.ActiveTokenName())
+	if !ok || IsEncryptedToken(value) {
+		return ""
+	}
+	return value
+}
+
+func ValidateTokenName(name string) error {
+	if !profileNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid token name %q: use letters, digits, '.', '_' or '-'", name)
+	}
+	return nil
+}
+
+func (c *Config) ActiveTokenName() string {
+	if c.ActiveToken != "" {
+		return c.ActiveToken
+	}
+	return DefaultTokenName
+}
+
+func (c *Config) Token(name string) (string, bool) {
+	value, ok := c.Tokens[name]
+	return value, ok && value != ""
+}
+
+func (c *Config) SetToken(name, value string) {
+	if c.Tokens == nil {
+		c.Tokens = make(map[string]string)
+	}
+	c.Tokens[name] = value
+}
+
+func (c *Config) RemoveToken(name string) bool {
+	if _, exists := c.Tokens[name]; !exists {
+		return false
+	}
+
+	delete(c.Tokens, name)
+	if len(c.Tokens) == 0 {
+		c.Tokens = nil
+	}
+	if c.ActiveToken == name {
+		c.ActiveToken = ""
+	}
+	return true
+}
+
+func (c *Config) TokenNames() []string {
+	names := make([]string, 0, len(c.Tokens))
+	for name := range c.Tokens {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
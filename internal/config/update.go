@@ -0,0 +1,40 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+const UpdateMirrorEnv = "GLM_UPDATE_MIRROR"
+
+var repoPattern = regexp.MustCompile(`^[A-Za-z0-9_.-]+/[A-Za-z0-9_.-]+$`)
+
+// ValidateUpdateMirror accepts "github" or "gitee" (optionally followed by
+// ":owner/repo"), an http(s) mirror URL, or a file:// URL or absolute path.
+func ValidateUpdateMirror(value string) error {
+	if kind, repo, ok := strings.Cut(value, ":"); kind == "github" || kind == "gitee" {
+		if ok && !repoPattern.MatchString(repo) {
+			return fmt.Errorf("invalid update mirror %q: expected %s:owner/repo", value, kind)
+		}
+		return nil
+	}
+
+	if filepath.IsAbs(value) {
+		return nil
+	}
+
+	u, err := url.Parse(value)
+	if err == nil {
+		switch {
+		case (u.Scheme == "http" || u.Scheme == "https") && u.Host != "":
+			return nil
+		case u.Scheme == "file" && u.Path != "":
+			return nil
+		}
+	}
+
+	return fmt.Errorf("invalid update mirror %q: use github, gitee, an http(s) URL, a file:// URL or an absolute directory", value)
+}
@@ -0,0 +1,239 @@
+package doctor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/xqsit94/glm/internal/api"
+	"github.com/xqsit94/glm/internal/config"
+	"github.com/xqsit94/glm/internal/glm"
+	"github.com/xqsit94/glm/internal/session"
+	"github.com/xqsit94/glm/internal/token"
+	"github.com/xqsit94/glm/pkg/paths"
+)
+
+type Status string
+
+const (
+	StatusOK   Status = "ok"
+	StatusWarn Status = "warn"
+	StatusFail Status = "fail"
+)
+
+type Check struct {
+	Name    string `json:"name"`
+	Status  Status `json:"status"`
+	Message string `json:"message"`
+	Hint    string `json:"hint,omitempty"`
+}
+
+type Report struct {
+	Checks []Check `json:"checks"`
+}
+
+type Options struct {
+	Profile     string
+	TokenName   string
+	SkipNetwork bool
+}
+
+var tokenPattern = regexp.MustCompile(`^[A-Za-z0-9]+\.[A-Za-z0-9]+$`)
+
+func (r *Report) add(name string, status Status, message, hint string) {
+	r.Checks = append(r.Checks, Check{Name: name, Status: status, Message: message, Hint: hint})
+}
+
+func (r *Report) Count(status Status) int {
+	count := 0
+	for _, check := range r.Checks {
+		if check.Status == status {
+			count++
+		}
+	}
+	return count
+}
+
+func (r *Report) Failed() bool {
+	return r.Count(StatusFail) > 0
+}
+
+func Run(opts Options) *Report {
+	report := &Report{}
+
+	cfg, err := config.Load()
+	configErr := err
+	if err != nil {
+		report.add("config-parse", StatusFail, err.Error(), "Run 'glm config edit' to fix it")
+		cfg = &config.Config{}
+	}
+
+	checkClaude(report)
+	checkNode(report)
+	checkConfigPermissions(report)
+
+	// An unreadable config already failed above; the session would only
+	// repeat that error.
+	var sess *session.Session
+	if configErr == nil {
+		sess = checkSession(report, opts)
+	}
+
+	checkStaleSettings(report)
+	checkEnvConflicts(report, cfg)
+
+	if sess != nil && !opts.SkipNetwork {
+		checkEndpoint(report, sess)
+	}
+
+	return report
+}
+
+func checkSession(report *Report, opts Options) *session.Session {
+	sess, err := session.New(session.Options{Profile: opts.Profile, TokenName: opts.TokenName, NoPrompt: true})
+
+	var tokenErr *session.TokenError
+	switch {
+	case errors.Is(err, token.ErrLocked):
+		report.add("token", StatusWarn, "stored tokens are encrypted and locked; skipping token and endpoint checks", "Run 'glm token unlock' or set GLM_PASSPHRASE")
+	case errors.As(err, &tokenErr):
+		report.add("token", StatusFail, err.Error(), "Run 'glm token set' to configure a token")
+	case err != nil:
+		report.add("session", StatusFail, err.Error(), "Check --profile, the project file and model settings with 'glm config list --show-origin'")
+	default:
+		checkToken(report, sess)
+	}
+
+	return sess
+}
+
+func commandVersion(name string, args ...string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	out, err := exec.CommandContext(ctx, name, args...).Output()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(strings.SplitN(string(out), "\n", 2)[0]), nil
+}
+
+func checkClaude(report *Report) {
+	path, err := exec.LookPath("claude")
+	if err != nil {
+		report.add("claude", StatusFail, "Claude Code is not on PATH", "Run 'glm install claude'")
+		return
+	}
+
+	version, err := commandVersion(path, "--version")
+	if err != nil {
+		report.add("claude", StatusWarn, fmt.Sprintf("found at %s, but 'claude --version' failed: %v", path, err), "")
+		return
+	}
+	report.add("claude", StatusOK, fmt.Sprintf("%s (%s)", version, path), "")
+}
+
+func checkNode(report *Report) {
+	nodeVersion, err := commandVersion("node", "--version")
+	if err != nil {
+		report.add("node", StatusWarn, "Node.js is not available", "Install Node.js from https://nodejs.org/ (needed to install or update Claude Code)")
+	} else {
+		report.add("node", StatusOK, "Node.js "+nodeVersion, "")
+	}
+
+	npmVersion, err := commandVersion("npm", "--version")
+	if err != nil {
+		report.add("npm", StatusWarn, "npm is not available", "npm ships with Node.js")
+	} else {
+		report.add("npm", StatusOK, "npm "+npmVersion, "")
+	}
+}
+
+func checkConfigPermissions(report *Report) {
+	configPath := paths.GetConfigPath()
+
+	info, err := os.Stat(configPath)
+	if os.IsNotExist(err) {
+		report.add("config", StatusOK, fmt.Sprintf("%s does not exist (using defaults)", configPath), "")
+		return
+	}
+	if err != nil {
+		report.add("config", StatusFail, fmt.Sprintf("cannot read %s: %v", configPath, err), "")
+		return
+	}
+
+	if mode := info.Mode().Perm(); mode&0077 != 0 {
+		report.add("config", StatusWarn, fmt.Sprintf("%s has permissions %04o; other users can read your token", configPath, mode), "Run: chmod 600 "+configPath)
+		return
+	}
+	report.add("config", StatusOK, fmt.Sprintf("%s (permissions %04o)", configPath, info.Mode().Perm()), "")
+}
+
+func checkToken(report *Report, sess *session.Session) {
+	value := sess.Token
+	switch {
+	case strings.TrimSpace(value) != value:
+		report.add("token", StatusFail, fmt.Sprintf("token from %s has leading or trailing whitespace", sess.TokenSource), "Run 'glm token set' to store it again")
+	case strings.ContainsAny(value, "\"'` "):
+		report.add("token", StatusFail, fmt.Sprintf("token from %s contains quotes or spaces", sess.TokenSource), "Check for a copy/paste mistake and run 'glm token set'")
+	case !tokenPattern.MatchString(value):
+		report.add("token", StatusWarn, fmt.Sprintf("token from %s does not look like a BigModel key ({id}.{secret})", sess.TokenSource), "")
+	default:
+		report.add("token", StatusOK, "from "+sess.TokenSource, "")
+	}
+}
+
+func checkStaleSettings(report *Report) {
+	settingsPath := paths.GetClaudeSettingsPath()
+
+	stale, err := glm.FindStaleSettings()
+	if err != nil {
+		report.add("claude-settings", StatusWarn, fmt.Sprintf("cannot inspect %s: %v", settingsPath, err), "")
+		return
+	}
+	if stale != nil {
+		report.add("claude-settings", StatusWarn, fmt.Sprintf("%s still contains GLM v1.0 keys: %s", settingsPath, strings.Join(stale.Keys, ", ")), "Run 'glm migrate'")
+		return
+	}
+	report.add("claude-settings", StatusOK, "no stale GLM keys in "+settingsPath, "")
+}
+
+func checkEnvConflicts(report *Report, cfg *config.Config) {
+	_, masked := session.Filter(os.Environ(), cfg.EnvAllow, cfg.EnvDeny)
+
+	var found []string
+	for _, name := range masked {
+		if name != "ANTHROPIC_AUTH_TOKEN" {
+			found = append(found, name)
+		}
+	}
+
+	if len(found) > 0 {
+		report.add("environment", StatusWarn, "conflicting variables exported in this shell: "+strings.Join(found, ", "), "glm masks them for its sessions; unset them or add them to env_allow")
+		return
+	}
+	report.add("environment", StatusOK, "no conflicting ANTHROPIC_* / CLAUDE_CODE_* variables", "")
+}
+
+func checkEndpoint(report *Report, sess *session.Session) {
+	result := api.Probe(context.Background(), sess.BaseURL, sess.AuthToken, sess.Model)
+	target := fmt.Sprintf("%s (profile %s)", sess.BaseURL, sess.Profile)
+
+	switch result.Verdict() {
+	case api.VerdictNetworkError:
+		report.add("endpoint", StatusFail, fmt.Sprintf("%s is unreachable: %v", target, result.Err), "Check your network or proxy settings")
+	case api.VerdictUnauthorized:
+		report.add("endpoint", StatusFail, fmt.Sprintf("%s rejected the token (HTTP %d) %s", target, result.StatusCode, result.Message), "Check the token and that it belongs to this endpoint's region")
+	case api.VerdictWrongEndpoint:
+		report.add("endpoint", StatusFail, fmt.Sprintf("%s does not look like an Anthropic-compatible endpoint (HTTP %d)", target, result.StatusCode), "Check the profile's base URL")
+	case api.VerdictValid:
+		report.add("endpoint", StatusOK, fmt.Sprintf("%s accepted the token (%dms)", target, result.Latency.Milliseconds()), "")
+	default:
+		report.add("endpoint", StatusWarn, fmt.Sprintf("%s answered HTTP %d %s", target, result.StatusCode, result.Message), "")
+	}
+}
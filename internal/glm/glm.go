@@ -8,37 +8,71 @@ import (
 	"github.com/xqsit94/glm/pkg/paths"
 )
 
-func Enable(model, token string) error {
-	claudeDir := paths.GetClaudeDir()
-
-	if err := os.MkdirAll(claudeDir, 0755); err != nil {
-		return fmt.Errorf("failed to create directory: %v", err)
+func Enable(baseURL, model, token string) error {
+	settings, err := config.LoadClaudeSettings()
+	if err != nil {
+		return err
 	}
 
-	settings := &config.ClaudeSettings{}
-	settings.Env.AnthropicBaseURL = "https://open.bigmodel.cn/api/anthropic"
-	settings.Env.AnthropicAuthToken = token
-	settings.Env.AnthropicModel = model
+	values := map[string]string{
+		"ANTHROPIC_BASE_URL":   baseURL,
+		"ANTHROPIC_AUTH_TOKEN": token,
+		"ANTHROPIC_MODEL":      model,
+	}
+	for _, key := range config.ManagedEnvKeys {
+		if err := settings.SetEnv(key, values[key]); err != nil {
+			return err
+		}
+	}
 
-	if err := config.SaveClaudeSettings(settings); err != nil {
+	backupPath, err := config.SaveClaudeSettings(settings)
+	if err != nil {
 		return err
 	}
 
+	if backupPath != "" {
+		fmt.Printf("Previous settings backed up to: %s\n", backupPath)
+	}
 	fmt.Printf("Claude settings have been configured successfully with model: %s\n", model)
 	return nil
 }
 
 func Disable() error {
 	claudeDir := paths.GetClaudeDir()
-	settingsPath := paths.GetClaudeSettingsPath()
 
-	if _, err := os.Stat(settingsPath); err == nil {
-		if err := os.Remove(settingsPath); err != nil {
-			return fmt.Errorf("failed to remove settings file: %v", err)
-		}
-		fmt.Println("Claude settings file has been removed.")
-	} else {
+	settings, err := config.LoadClaudeSettings()
+	if err != nil {
+		return err
+	}
+
+	if !settings.Exists() {
 		fmt.Println("Claude settings file not found.")
+	} else {
+		removed, err := removeManagedKeys(settings)
+		if err != nil {
+			return err
+		}
+
+		var backupPath string
+		if settings.IsEmpty() {
+			backupPath, err = config.RemoveClaudeSettings(settings)
+			if err == nil {
+				fmt.Println("Claude settings file has been removed.")
+			}
+		} else if len(removed) > 0 {
+			backupPath, err = config.SaveClaudeSettings(settings)
+			if err == nil {
+				fmt.Println("GLM settings have been removed from the Claude settings file.")
+			}
+		} else {
+			fmt.Println("No GLM settings found in the Claude settings file.")
+		}
+		if err != nil {
+			return err
+		}
+		if backupPath != "" {
+			fmt.Printf("Previous settings backed up to: %s\n", backupPath)
+		}
 	}
 
 	if entries, err := os.ReadDir(claudeDir); err == nil {
@@ -62,9 +96,15 @@ func SetModel(model string) error {
 		return err
 	}
 
-	settings.Env.AnthropicModel = model
+	if _, ok := settings.Env("ANTHROPIC_BASE_URL"); !ok {
+		return fmt.Errorf("GLM is not enabled. Run 'glm enable' first")
+	}
+
+	if err := settings.SetEnv("ANTHROPIC_MODEL", model); err != nil {
+		return err
+	}
 
-	if err := config.SaveClaudeSettings(settings); err != nil {
+	if _, err := config.SaveClaudeSettings(settings); err != nil {
 		return err
 	}
 
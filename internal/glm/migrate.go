@@ -0,0 +1,178 @@
+package glm
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/xqsit94/glm/internal/config"
+	"github.com/xqsit94/glm/pkg/paths"
+)
+
+type StaleSettings struct {
+	settings *config.ClaudeSettings
+	before   []byte
+	after    []byte
+	token    string
+	Keys     []string
+}
+
+// FindStaleSettings reports the env entries a v1.0 'glm enable' left in
+// Claude's settings file. Entries are only considered stale when the base URL
+// points at a GLM endpoint, so hand-configured gateways are left alone.
+func FindStaleSettings() (*StaleSettings, error) {
+	settings, err := config.LoadClaudeSettings()
+	if err != nil {
+		return nil, err
+	}
+
+	baseURL, ok := settings.Env("ANTHROPIC_BASE_URL")
+	if !ok || !isGLMBaseURL(baseURL) {
+		return nil, nil
+	}
+
+	before, err := settings.Marshal()
+	if err != nil {
+		return nil, err
+	}
+	token, _ := settings.Env("ANTHROPIC_AUTH_TOKEN")
+
+	keys, err := removeManagedKeys(settings)
+	if err != nil {
+		return nil, err
+	}
+
+	after, err := settings.Marshal()
+	if err != nil {
+		return nil, err
+	}
+
+	return &StaleSettings{settings: settings, before: before, after: after, token: token, Keys: keys}, nil
+}
+
+func isGLMBaseURL(baseURL string) bool {
+	cfg, err := config.Load()
+	if err != nil {
+		cfg = &config.Config{}
+	}
+
+	for _, name := range cfg.ProfileNames() {
+		if p, err := cfg.Profile(name); err == nil && strings.TrimRight(p.BaseURL, "/") == strings.TrimRight(baseURL, "/") {
+			return true
+		}
+	}
+	return false
+}
+
+func removeManagedKeys(settings *config.ClaudeSettings) ([]string, error) {
+	var removed []string
+	for _, key := range config.ManagedEnvKeys {
+		ok, err := settings.UnsetEnv(key)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			removed = append(removed, key)
+		}
+	}
+	return removed, nil
+}
+
+func (s *StaleSettings) Diff() string {
+	diff := lineDiff(string(s.before), string(s.after))
+	if s.token != "" {
+		diff = strings.ReplaceAll(diff, s.token, config.MaskSecret(s.token))
+	}
+	return diff
+}
+
+func (s *StaleSettings) Apply() (string, error) {
+	if s.settings.IsEmpty() {
+		return config.RemoveClaudeSettings(s.settings)
+	}
+	return config.SaveClaudeSettings(s.settings)
+}
+
+func Migrate(dryRun, assumeYes bool) error {
+	stale, err := FindStaleSettings()
+	if err != nil {
+		return err
+	}
+
+	settingsPath := paths.GetClaudeSettingsPath()
+	if stale == nil {
+		fmt.Printf("✅ No leftover GLM settings found in %s\n", settingsPath)
+		return nil
+	}
+
+	fmt.Printf("🔍 Found leftover GLM v1.0 settings in %s:\n\n", settingsPath)
+	fmt.Println(stale.Diff())
+
+	if dryRun {
+		fmt.Println("💡 Dry run: no changes were made. Run 'glm migrate' to apply.")
+		return nil
+	}
+
+	if !assumeYes && !confirm("Remove these entries? (y/N): ") {
+		fmt.Println("Migration cancelled.")
+		return nil
+	}
+
+	return applyMigration(stale, settingsPath)
+}
+
+func applyMigration(stale *StaleSettings, settingsPath string) error {
+	backupPath, err := stale.Apply()
+	if err != nil {
+		return err
+	}
+
+	if stale.settings.Exists() {
+		fmt.Printf("✅ Removed %s from %s\n", strings.Join(stale.Keys, ", "), settingsPath)
+	} else {
+		fmt.Printf("✅ Removed %s (it only contained GLM settings)\n", settingsPath)
+	}
+	if backupPath != "" {
+		fmt.Printf("📦 Backup saved to: %s\n", backupPath)
+	}
+	return nil
+}
+
+// OfferMigration asks once per glm version whether to clean up stale v1.0
+// settings. It never fails the caller; problems are reported and skipped.
+func OfferMigration(version string) {
+	cfg, err := config.Load()
+	if err != nil || cfg.MigrationChecked == version {
+		return
+	}
+
+	cfg.MigrationChecked = version
+	if err := config.Save(cfg); err != nil {
+		return
+	}
+
+	stale, err := FindStaleSettings()
+	if err != nil || stale == nil {
+		return
+	}
+
+	settingsPath := paths.GetClaudeSettingsPath()
+	fmt.Printf("⚠️  %s still contains GLM v1.0 settings (%s).\n", settingsPath, strings.Join(stale.Keys, ", "))
+	fmt.Println("   They make plain 'claude' use GLM too.")
+	if !confirm("Remove them now? A backup will be kept. (y/N): ") {
+		fmt.Println("💡 Run 'glm migrate' any time to clean them up.")
+		return
+	}
+
+	if err := applyMigration(stale, settingsPath); err != nil {
+		fmt.Printf("❌ Migration failed: %v\n", err)
+	}
+}
+
+func confirm(prompt string) bool {
+	fmt.Print(prompt)
+	response, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	response = strings.ToLower(strings.TrimSpace(response))
+	return response == "y" || response == "yes"
+}
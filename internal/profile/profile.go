@@ -0,0 +1,151 @@
+package profile
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/xqsit94/glm/internal/config"
+)
+
+func Add(name string, p config.Profile, overwrite bool) error {
+	if err := config.ValidateProfile(name, &p); err != nil {
+		return err
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+
+	if p.TokenName != "" {
+		if _, ok := cfg.Token(p.TokenName); !ok {
+			return fmt.Errorf("token %q not found. Run 'glm token set --name %s' first", p.TokenName, p.TokenName)
+		}
+	}
+
+	if _, exists := cfg.Profiles[name]; exists && !overwrite {
+		return fmt.Errorf("profile %q already exists. Use --force to overwrite it", name)
+	}
+
+	if cfg.Profiles == nil {
+		cfg.Profiles = make(map[string]config.Profile)
+	}
+	cfg.Profiles[name] = p
+
+	if err := config.Save(cfg); err != nil {
+		return err
+	}
+
+	if config.IsBuiltinProfile(name) {
+		fmt.Printf("✅ Profile '%s' saved (overrides the built-in profile).\n", name)
+	} else {
+		fmt.Printf("✅ Profile '%s' saved.\n", name)
+	}
+	return nil
+}
+
+func List() error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+
+	active, err := cfg.Profile("")
+	if err != nil {
+		return err
+	}
+
+	for _, name := range cfg.ProfileNames() {
+		p, err := cfg.Profile(name)
+		if err != nil {
+			return err
+		}
+
+		marker := "  "
+		if name == active.Name {
+			marker = "* "
+		}
+
+		var details []string
+		if p.DefaultModel != "" {
+			details = append(details, "model: "+p.DefaultModel)
+		}
+		if p.TokenName != "" {
+			details = append(details, "token: "+p.TokenName)
+		}
+		if p.TokenEnv != "" {
+			details = append(details, "token: $"+p.TokenEnv)
+		}
+		if p.AuthMode != "" {
+			details = append(details, "auth: "+p.AuthMode)
+		}
+		if len(p.Env) > 0 {
+			keys := make([]string, 0, len(p.Env))
+			for key := range p.Env {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			details = append(details, "env: "+strings.Join(keys, ","))
+		}
+		if _, custom := cfg.Profiles[name]; !custom {
+			details = append(details, "built-in")
+		}
+
+		line := fmt.Sprintf("%s%-12s %s", marker, name, p.BaseURL)
+		if len(details) > 0 {
+			line += " (" + strings.Join(details, ", ") + ")"
+		}
+		fmt.Println(line)
+	}
+
+	return nil
+}
+
+func Use(name string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+
+	if _, err := cfg.Profile(name); err != nil {
+		return err
+	}
+
+	cfg.ActiveProfile = name
+	if err := config.Save(cfg); err != nil {
+		return err
+	}
+
+	fmt.Printf("✅ Now using profile '%s'.\n", name)
+	return nil
+}
+
+func Remove(name string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+
+	if _, exists := cfg.Profiles[name]; !exists {
+		if config.IsBuiltinProfile(name) {
+			return fmt.Errorf("profile %q is built-in and cannot be removed", name)
+		}
+		return fmt.Errorf("profile %q not found", name)
+	}
+
+	delete(cfg.Profiles, name)
+	if len(cfg.Profiles) == 0 {
+		cfg.Profiles = nil
+	}
+	if cfg.ActiveProfile == name && !config.IsBuiltinProfile(name) {
+		cfg.ActiveProfile = ""
+	}
+
+	if err := config.Save(cfg); err != nil {
+		return err
+	}
+
+	fmt.Printf("✅ Profile '%s' has been removed.\n", name)
+	return nil
+}
@@ -0,0 +1,277 @@
+package proxy
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const maxInspectBytes = 1 << 20
+
+type Options struct {
+	Upstream string
+	// Token returns the upstream token for each request, so short-lived
+	// tokens can be refreshed while the proxy runs.
+	Token    func() (string, error)
+	LocalKey string
+	LogPath  string
+}
+
+type Server struct {
+	upstream *url.URL
+	token    func() (string, error)
+	localKey string
+	proxy    *httputil.ReverseProxy
+	server   *http.Server
+	listener net.Listener
+
+	logMu   sync.Mutex
+	logFile *os.File
+}
+
+type LogEntry struct {
+	Time         time.Time `json:"time"`
+	Method       string    `json:"method"`
+	Path         string    `json:"path"`
+	Model        string    `json:"model,omitempty"`
+	Stream       bool      `json:"stream,omitempty"`
+	Status       int       `json:"status"`
+	LatencyMS    int64     `json:"latency_ms"`
+	InputTokens  int64     `json:"input_tokens,omitempty"`
+	OutputTokens int64     `json:"output_tokens,omitempty"`
+	Error        string    `json:"error,omitempty"`
+}
+
+type contextKey struct{}
+
+type requestInfo struct {
+	token  string
+	start  time.Time
+	method string
+	path   string
+	model  string
+	stream bool
+}
+
+func GenerateLocalKey() (string, error) {
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate local key: %v", err)
+	}
+	return "glm-local-" + hex.EncodeToString(buf), nil
+}
+
+func New(opts Options) (*Server, error) {
+	upstream, err := url.Parse(opts.Upstream)
+	if err != nil || upstream.Scheme == "" || upstream.Host == "" {
+		return nil, fmt.Errorf("invalid upstream URL %q", opts.Upstream)
+	}
+	if opts.Token == nil {
+		return nil, fmt.Errorf("upstream token is required")
+	}
+	if opts.LocalKey == "" {
+		return nil, fmt.Errorf("local key is required")
+	}
+
+	s := &Server{
+		upstream: upstream,
+		token:    opts.Token,
+		localKey: opts.LocalKey,
+	}
+
+	if opts.LogPath != "" {
+		if err := os.MkdirAll(filepath.Dir(opts.LogPath), 0700); err != nil {
+			return nil, fmt.Errorf("failed to create log directory: %v", err)
+		}
+		logFile, err := os.OpenFile(opts.LogPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open log file: %v", err)
+		}
+		s.logFile = logFile
+	}
+
+	s.proxy = &httputil.ReverseProxy{
+		Rewrite:        s.rewrite,
+		FlushInterval:  -1,
+		ModifyResponse: s.modifyResponse,
+		ErrorHandler:   s.handleError,
+	}
+
+	return s, nil
+}
+
+func (s *Server) Start(addr string) (string, error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return "", fmt.Errorf("failed to listen on %s: %v", addr, err)
+	}
+
+	s.listener = listener
+	s.server = &http.Server{Handler: s, ReadHeaderTimeout: 30 * time.Second}
+
+	go s.server.Serve(listener)
+
+	return s.URL(), nil
+}
+
+func (s *Server) URL() string {
+	if s.listener == nil {
+		return ""
+	}
+	return "http://" + s.listener.Addr().String()
+}
+
+func (s *Server) Close() error {
+	var err error
+	if s.server != nil {
+		err = s.server.Close()
+	}
+	if s.logFile != nil {
+		s.logFile.Close()
+	}
+	return err
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !s.authorized(r) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnauthorized)
+		io.WriteString(w, `{"type":"error","error":{"type":"authentication_error","message":"invalid local proxy key"}}`)
+		return
+	}
+
+	info := &requestInfo{start: time.Now(), method: r.Method, path: r.URL.Path}
+
+	token, err := s.token()
+	if err != nil {
+		s.log(LogEntry{Time: info.start, Method: info.method, Path: info.path, Status: http.StatusBadGateway, Error: err.Error()})
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadGateway)
+		json.NewEncoder(w).Encode(map[string]any{
+			"type":  "error",
+			"error": map[string]string{"type": "api_error", "message": "glm proxy: " + err.Error()},
+		})
+		return
+	}
+	info.token = token
+
+	if r.Body != nil && r.Method == http.MethodPost {
+		body, err := io.ReadAll(io.LimitReader(r.Body, maxInspectBytes))
+		if err != nil {
+			http.Error(w, "failed to read request body", http.StatusBadRequest)
+			return
+		}
+		var payload struct {
+			Model  string `json:"model"`
+			Stream bool   `json:"stream"`
+		}
+		json.Unmarshal(body, &payload)
+		info.model = payload.Model
+		info.stream = payload.Stream
+
+		r.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(bytes.NewReader(body), r.Body), r.Body}
+	}
+
+	s.proxy.ServeHTTP(w, r.WithContext(contextWithInfo(r, info)))
+}
+
+func (s *Server) authorized(r *http.Request) bool {
+	key := r.Header.Get("x-api-key")
+	if key == "" {
+		key = strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	}
+	return subtle.ConstantTimeCompare([]byte(key), []byte(s.localKey)) == 1
+}
+
+func (s *Server) rewrite(pr *httputil.ProxyRequest) {
+	pr.SetURL(s.upstream)
+	pr.Out.Host = s.upstream.Host
+	pr.Out.Header.Del("Accept-Encoding")
+
+	var token string
+	if info := infoFromContext(pr.In); info != nil {
+		token = info.token
+	}
+
+	if pr.In.Header.Get("x-api-key") != "" {
+		pr.Out.Header.Set("x-api-key", token)
+		pr.Out.Header.Del("Authorization")
+	} else {
+		pr.Out.Header.Set("Authorization", "Bearer "+token)
+		pr.Out.Header.Del("x-api-key")
+	}
+}
+
+func (s *Server) modifyResponse(resp *http.Response) error {
+	info := infoFromContext(resp.Request)
+	if info == nil {
+		return nil
+	}
+
+	resp.Body = &usageReader{
+		ReadCloser: resp.Body,
+		sse:        strings.HasPrefix(resp.Header.Get("Content-Type"), "text/event-stream"),
+		onClose: func(usage usage) {
+			s.log(LogEntry{
+				Time:         info.start,
+				Method:       info.method,
+				Path:         info.path,
+				Model:        info.model,
+				Stream:       info.stream,
+				Status:       resp.StatusCode,
+				LatencyMS:    time.Since(info.start).Milliseconds(),
+				InputTokens:  usage.InputTokens,
+				OutputTokens: usage.OutputTokens,
+			})
+		},
+	}
+
+	return nil
+}
+
+func (s *Server) handleError(w http.ResponseWriter, r *http.Request, err error) {
+	if info := infoFromContext(r); info != nil {
+		s.log(LogEntry{
+			Time:      info.start,
+			Method:    info.method,
+			Path:      info.path,
+			Model:     info.model,
+			Stream:    info.stream,
+			Status:    http.StatusBadGateway,
+			LatencyMS: time.Since(info.start).Milliseconds(),
+			Error:     err.Error(),
+		})
+	}
+	w.WriteHeader(http.StatusBadGateway)
+}
+
+func (s *Server) log(entry LogEntry) {
+	if s.logFile == nil {
+		return
+	}
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+
+	s.logMu.Lock()
+	defer s.logMu.Unlock()
+	s.logFile.Write(append(data, '\n'))
+}
@@ -0,0 +1,222 @@
+package proxy
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+const (
+	testLocalKey     = "glm-local-test"
+	testUpstreamAuth = "real.secret"
+)
+
+// fakeUpstream streams an Anthropic-style SSE response. It sends
+// message_start, then waits for release before finishing, so a test can
+// see whether the first event arrives before the response is complete.
+type fakeUpstream struct {
+	*httptest.Server
+	release chan struct{}
+
+	mu      sync.Mutex
+	auth    []string
+	apiKeys []string
+	bodies  []string
+}
+
+func newFakeUpstream(t *testing.T) *fakeUpstream {
+	t.Helper()
+	u := &fakeUpstream{release: make(chan struct{})}
+	u.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		u.mu.Lock()
+		u.auth = append(u.auth, r.Header.Get("Authorization"))
+		u.apiKeys = append(u.apiKeys, r.Header.Get("x-api-key"))
+		u.bodies = append(u.bodies, string(body))
+		u.mu.Unlock()
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "event: message_start\ndata: {\"type\":\"message_start\",\"message\":{\"usage\":{\"input_tokens\":12,\"output_tokens\":1}}}\n\n")
+		w.(http.Flusher).Flush()
+
+		select {
+		case <-u.release:
+		case <-r.Context().Done():
+			return
+		}
+
+		fmt.Fprint(w, "event: message_delta\ndata: {\"type\":\"message_delta\",\"usage\":{\"output_tokens\":34}}\n\n")
+		fmt.Fprint(w, "event: message_stop\ndata: {\"type\":\"message_stop\"}\n\n")
+	}))
+	t.Cleanup(u.Close)
+	return u
+}
+
+func (u *fakeUpstream) requests() ([]string, []string, []string) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	return append([]string(nil), u.auth...), append([]string(nil), u.apiKeys...), append([]string(nil), u.bodies...)
+}
+
+func startProxy(t *testing.T, upstream string) (*Server, string) {
+	t.Helper()
+	logPath := filepath.Join(t.TempDir(), "proxy.log")
+
+	s, err := New(Options{
+		Upstream: upstream,
+		Token:    func() (string, error) { return testUpstreamAuth, nil },
+		LocalKey: testLocalKey,
+		LogPath:  logPath,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Start("127.0.0.1:0"); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { s.Close() })
+	return s, logPath
+}
+
+func postMessages(t *testing.T, proxyURL string, header, value string) *http.Response {
+	t.Helper()
+	body := `{"model":"glm-4.6","stream":true,"max_tokens":16,"messages":[{"role":"user","content":"hi"}]}`
+	req, err := http.NewRequest(http.MethodPost, proxyURL+"/v1/messages", strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(header, value)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+	return resp
+}
+
+func TestProxyRejectsWrongLocalKey(t *testing.T) {
+	upstream := newFakeUpstream(t)
+	s, _ := startProxy(t, upstream.URL)
+
+	for _, tc := range []struct{ header, value string }{
+		{"x-api-key", "wrong"},
+		{"Authorization", "Bearer " + testUpstreamAuth},
+		{"Authorization", ""},
+	} {
+		resp := postMessages(t, s.URL(), tc.header, tc.value)
+		if resp.StatusCode != http.StatusUnauthorized {
+			t.Errorf("%s %q: status %d, want 401", tc.header, tc.value, resp.StatusCode)
+		}
+	}
+
+	if auth, _, _ := upstream.requests(); len(auth) != 0 {
+		t.Errorf("rejected requests reached the upstream: %d", len(auth))
+	}
+}
+
+func TestProxyStreamsWithRealToken(t *testing.T) {
+	upstream := newFakeUpstream(t)
+	s, logPath := startProxy(t, upstream.URL)
+
+	resp := postMessages(t, s.URL(), "x-api-key", testLocalKey)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status %d, want 200", resp.StatusCode)
+	}
+
+	// The upstream holds the rest of the stream until released, so the first
+	// event can only arrive here if the proxy does not buffer.
+	reader := bufio.NewReader(resp.Body)
+	firstEvent := make(chan string, 1)
+	go func() {
+		line, _ := reader.ReadString('\n')
+		firstEvent <- line
+	}()
+
+	select {
+	case line := <-firstEvent:
+		if !strings.Contains(line, "message_start") {
+			t.Errorf("first line = %q, want the message_start event", line)
+		}
+	case <-time.After(5 * time.Second):
+		close(upstream.release)
+		t.Fatal("the first event was buffered by the proxy")
+	}
+	close(upstream.release)
+
+	rest, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(rest), "message_stop") {
+		t.Errorf("stream ended early: %q", rest)
+	}
+
+	auth, apiKeys, bodies := upstream.requests()
+	if len(apiKeys) != 1 || apiKeys[0] != testUpstreamAuth {
+		t.Errorf("upstream x-api-key = %q, want the real token", apiKeys)
+	}
+	if len(auth) != 1 || auth[0] != "" {
+		t.Errorf("upstream Authorization = %q, want none", auth)
+	}
+	if len(bodies) != 1 || !strings.Contains(bodies[0], `"model":"glm-4.6"`) {
+		t.Errorf("upstream body = %q, want the original request", bodies)
+	}
+
+	entry := waitForLogEntry(t, logPath)
+	if entry.Model != "glm-4.6" || !entry.Stream || entry.Status != http.StatusOK {
+		t.Errorf("log entry = %+v, want model glm-4.6, stream, status 200", entry)
+	}
+	if entry.InputTokens != 12 || entry.OutputTokens != 34 {
+		t.Errorf("log usage = %d in / %d out, want 12 / 34", entry.InputTokens, entry.OutputTokens)
+	}
+}
+
+func TestProxyBearerAuth(t *testing.T) {
+	upstream := newFakeUpstream(t)
+	close(upstream.release)
+	s, _ := startProxy(t, upstream.URL)
+
+	resp := postMessages(t, s.URL(), "Authorization", "Bearer "+testLocalKey)
+	io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status %d, want 200", resp.StatusCode)
+	}
+
+	auth, apiKeys, _ := upstream.requests()
+	if len(auth) != 1 || auth[0] != "Bearer "+testUpstreamAuth {
+		t.Errorf("upstream Authorization = %q, want the real token", auth)
+	}
+	if len(apiKeys) != 1 || apiKeys[0] != "" {
+		t.Errorf("upstream x-api-key = %q, want none", apiKeys)
+	}
+}
+
+func waitForLogEntry(t *testing.T, path string) LogEntry {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		data, _ := os.ReadFile(path)
+		if line, _, ok := strings.Cut(string(data), "\n"); ok {
+			var entry LogEntry
+			if err := json.Unmarshal([]byte(line), &entry); err != nil {
+				t.Fatalf("invalid log line %q: %v", line, err)
+			}
+			return entry
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("no log entry written to %s", path)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
@@ -0,0 +1,112 @@
+package proxy
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"sync"
+)
+
+type usage struct {
+	InputTokens  int64 `json:"input_tokens"`
+	OutputTokens int64 `json:"output_tokens"`
+}
+
+type usageReader struct {
+	io.ReadCloser
+	sse     bool
+	onClose func(usage usage)
+
+	buf   bytes.Buffer
+	usage usage
+	once  sync.Once
+}
+
+func (u *usageReader) Read(p []byte) (int, error) {
+	n, err := u.ReadCloser.Read(p)
+	if n > 0 {
+		u.inspect(p[:n])
+	}
+	return n, err
+}
+
+func (u *usageReader) Close() error {
+	err := u.ReadCloser.Close()
+	u.once.Do(func() {
+		if !u.sse {
+			u.parseJSON(u.buf.Bytes())
+		}
+		u.onClose(u.usage)
+	})
+	return err
+}
+
+func (u *usageReader) inspect(chunk []byte) {
+	if !u.sse {
+		if u.buf.Len() < maxInspectBytes {
+			u.buf.Write(chunk)
+		}
+		return
+	}
+
+	u.buf.Write(chunk)
+	for {
+		line, err := u.buf.ReadBytes('\n')
+		if err != nil {
+			u.buf.Write(line)
+			return
+		}
+		if data, ok := bytes.CutPrefix(bytes.TrimSpace(line), []byte("data:")); ok {
+			u.parseEvent(bytes.TrimSpace(data))
+		}
+	}
+}
+
+func (u *usageReader) parseEvent(data []byte) {
+	var event struct {
+		Type    string `json:"type"`
+		Message struct {
+			Usage usage `json:"usage"`
+		} `json:"message"`
+		Usage usage `json:"usage"`
+	}
+	if json.Unmarshal(data, &event) != nil {
+		return
+	}
+
+	switch event.Type {
+	case "message_start":
+		u.usage.InputTokens = event.Message.Usage.InputTokens
+		u.usage.OutputTokens = event.Message.Usage.OutputTokens
+	case "message_delta":
+		if event.Usage.InputTokens > 0 {
+			u.usage.InputTokens = event.Usage.InputTokens
+		}
+		if event.Usage.OutputTokens > 0 {
+			u.usage.OutputTokens = event.Usage.OutputTokens
+		}
+	}
+}
+
+func (u *usageReader) parseJSON(data []byte) {
+	var body struct {
+		Usage usage `json:"usage"`
+	}
+	if json.Unmarshal(data, &body) == nil {
+		u.usage = body.Usage
+	}
+}
+
+func contextWithInfo(r *http.Request, info *requestInfo) context.Context {
+	return context.WithValue(r.Context(), contextKey{}, info)
+}
+
+func infoFromContext(r *http.Request) *requestInfo {
+	if r == nil {
+		return nil
+	}
+	info, _ := r.Context().Value(contextKey{}).(*requestInfo)
+	return info
+}
@@ -0,0 +1,21 @@
+//go:build !unix
+
+package runner
+
+import (
+	"os"
+)
+
+var forwardedSignals = []os.Signal{os.Interrupt}
+
+func Exec(name string, args, env []string) error {
+	return Run(name, args, env)
+}
+
+func shouldForward(sig os.Signal) bool {
+	return false
+}
+
+func exitCode(state *os.ProcessState) int {
+	return state.ExitCode()
+}
@@ -0,0 +1,53 @@
+//go:build unix
+
+package runner
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+var forwardedSignals = []os.Signal{
+	syscall.SIGINT,
+	syscall.SIGTERM,
+	syscall.SIGHUP,
+	syscall.SIGWINCH,
+}
+
+// Exec replaces the current process with name, so the child inherits our
+// PID, terminal and signals directly and its exit status is our own.
+func Exec(name string, args, env []string) error {
+	path, err := exec.LookPath(name)
+	if err != nil {
+		return fmt.Errorf("failed to run %s: %v", name, err)
+	}
+
+	if err := syscall.Exec(path, append([]string{name}, args...), env); err != nil {
+		return fmt.Errorf("failed to run %s: %v", name, err)
+	}
+
+	return nil
+}
+
+// Terminal-generated SIGINT and SIGWINCH already reach every process in the
+// foreground process group, including the child; forwarding them again would
+// deliver them twice.
+func shouldForward(sig os.Signal) bool {
+	if sig != syscall.SIGINT && sig != syscall.SIGWINCH {
+		return true
+	}
+
+	pgrp, err := unix.IoctlGetInt(int(os.Stdin.Fd()), unix.TIOCGPGRP)
+	return err != nil || pgrp != syscall.Getpgrp()
+}
+
+func exitCode(state *os.ProcessState) int {
+	if status, ok := state.Sys().(syscall.WaitStatus); ok && status.Signaled() {
+		return 128 + int(status.Signal())
+	}
+	return state.ExitCode()
+}
@@ -0,0 +1,117 @@
+package session
+
+import (
+	"os"
+	"path"
+	"sort"
+	"strings"
+)
+
+// DeniedVars are inherited variables that would redirect or re-authenticate
+// Claude Code away from the GLM endpoint. Entries may use '*' wildcards.
+var DeniedVars = []string{
+	"ANTHROPIC_API_KEY",
+	"ANTHROPIC_AUTH_TOKEN",
+	"ANTHROPIC_BASE_URL",
+	"ANTHROPIC_MODEL",
+	"ANTHROPIC_SMALL_FAST_MODEL",
+	"ANTHROPIC_DEFAULT_*_MODEL",
+	"ANTHROPIC_CUSTOM_HEADERS",
+	"ANTHROPIC_BEDROCK_BASE_URL",
+	"ANTHROPIC_VERTEX_*",
+	"CLAUDE_CODE_USE_BEDROCK",
+	"CLAUDE_CODE_USE_VERTEX",
+	"CLAUDE_CODE_SKIP_BEDROCK_AUTH",
+	"CLAUDE_CODE_SKIP_VERTEX_AUTH",
+}
+
+func matchesAny(name string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Filter removes denied variables from environ. Variables matching allow are
+// always kept; deny extends DeniedVars. It returns the kept entries and the
+// names that were removed.
+func Filter(environ, allow, deny []string) ([]string, []string) {
+	denied := append(append([]string{}, DeniedVars...), deny...)
+
+	var kept, masked []string
+	for _, entry := range environ {
+		name, _, _ := strings.Cut(entry, "=")
+		if matchesAny(name, denied) && !matchesAny(name, allow) {
+			masked = append(masked, name)
+			continue
+		}
+		kept = append(kept, entry)
+	}
+
+	return kept, masked
+}
+
+// Environ builds the child environment: the inherited environment minus
+// denied variables and anything the session sets itself, followed by the
+// session variables. It also returns the inherited variables it masked whose
+// values differed from the session's, so callers can warn about them.
+func (s *Session) Environ() ([]string, []string) {
+	vars := s.Vars()
+	environ := os.Environ()
+
+	inherited := make(map[string]string)
+	for _, entry := range environ {
+		name, value, _ := strings.Cut(entry, "=")
+		inherited[name] = value
+	}
+
+	kept, masked := Filter(environ, s.allow, s.deny)
+
+	env := make([]string, 0, len(kept)+len(vars))
+	for _, entry := range kept {
+		name, _, _ := strings.Cut(entry, "=")
+		if _, overridden := vars[name]; overridden {
+			masked = append(masked, name)
+			continue
+		}
+		env = append(env, entry)
+	}
+
+	keys := make([]string, 0, len(vars))
+	for key := range vars {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		env = append(env, key+"="+vars[key])
+	}
+
+	var conflicts []string
+	for _, name := range masked {
+		if value, ok := vars[name]; !ok || value != inherited[name] {
+			conflicts = append(conflicts, name)
+		}
+	}
+	sort.Strings(conflicts)
+
+	return env, conflicts
+}
+
+// Unset returns the inherited variables that Filter would mask and the
+// session does not set itself. An exported environment must remove them,
+// since the shell it is loaded into still has them.
+func (s *Session) Unset() []string {
+	vars := s.Vars()
+	_, masked := Filter(os.Environ(), s.allow, s.deny)
+
+	var names []string
+	for _, name := range masked {
+		if _, ok := vars[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,132 @@
+package session
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/xqsit94/glm/internal/config"
+)
+
+var Formats = []string{"shell", "dotenv", "json"}
+
+var Shells = []string{"bash", "zsh", "sh", "fish", "powershell"}
+
+func DetectShell(shellPath string) string {
+	name := filepath.Base(shellPath)
+	for _, shell := range Shells {
+		if name == shell {
+			return shell
+		}
+	}
+	if name == "pwsh" {
+		return "powershell"
+	}
+	return "sh"
+}
+
+func (s *Session) Export(format, shell string, reveal bool) (string, error) {
+	vars := s.Vars()
+	if !reveal {
+		vars["ANTHROPIC_AUTH_TOKEN"] = config.MaskSecret(vars["ANTHROPIC_AUTH_TOKEN"])
+	}
+
+	keys := make([]string, 0, len(vars))
+	for key := range vars {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	unset := s.Unset()
+
+	var b strings.Builder
+	switch format {
+	case "shell":
+		for _, key := range unset {
+			line, err := shellUnset(shell, key)
+			if err != nil {
+				return "", err
+			}
+			b.WriteString(line + "\n")
+		}
+		for _, key := range keys {
+			line, err := shellExport(shell, key, vars[key])
+			if err != nil {
+				return "", err
+			}
+			b.WriteString(line + "\n")
+		}
+	case "dotenv":
+		// dotenv has no way to remove a variable, so list them for the reader.
+		for _, key := range unset {
+			fmt.Fprintf(&b, "# unset %s\n", key)
+		}
+		for _, key := range keys {
+			fmt.Fprintf(&b, "%s=%s\n", key, dotenvQuote(vars[key]))
+		}
+	case "json":
+		// Variables to remove are null.
+		values := make(map[string]*string, len(vars)+len(unset))
+		for key, value := range vars {
+			values[key] = &value
+		}
+		for _, key := range unset {
+			values[key] = nil
+		}
+		data, err := json.MarshalIndent(values, "", "  ")
+		if err != nil {
+			return "", fmt.Errorf("failed to marshal environment: %v", err)
+		}
+		b.Write(data)
+		b.WriteString("\n")
+	default:
+		return "", fmt.Errorf("unsupported format %q (supported: %s)", format, strings.Join(Formats, ", "))
+	}
+
+	return b.String(), nil
+}
+
+func shellExport(shell, key, value string) (string, error) {
+	switch shell {
+	case "bash", "zsh", "sh":
+		return fmt.Sprintf("export %s=%s", key, posixQuote(value)), nil
+	case "fish":
+		return fmt.Sprintf("set -gx %s %s", key, fishQuote(value)), nil
+	case "powershell":
+		return fmt.Sprintf("$env:%s = %s", key, powershellQuote(value)), nil
+	default:
+		return "", fmt.Errorf("unsupported shell %q (supported: %s)", shell, strings.Join(Shells, ", "))
+	}
+}
+
+func shellUnset(shell, key string) (string, error) {
+	switch shell {
+	case "bash", "zsh", "sh":
+		return "unset " + key, nil
+	case "fish":
+		return "set -e " + key, nil
+	case "powershell":
+		return fmt.Sprintf("Remove-Item Env:%s -ErrorAction SilentlyContinue", key), nil
+	default:
+		return "", fmt.Errorf("unsupported shell %q (supported: %s)", shell, strings.Join(Shells, ", "))
+	}
+}
+
+func posixQuote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
+
+func fishQuote(value string) string {
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	return "'" + strings.ReplaceAll(value, "'", `\'`) + "'"
+}
+
+func powershellQuote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+}
+
+func dotenvQuote(value string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "$", `\$`)
+	return `"` + replacer.Replace(value) + `"`
+}
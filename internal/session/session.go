@@ -0,0 +1,208 @@
+package session
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/xqsit94/glm/internal/config"
+	"github.com/xqsit94/glm/internal/token"
+)
+
+type Options struct {
+	Profile   string
+	Model     string
+	Models    config.ModelMap
+	TokenName string
+	NoPrompt  bool
+}
+
+type Session struct {
+	Profile     string
+	BaseURL     string
+	Token       string
+	TokenSource string
+	AuthMode    string
+	AuthTTL     time.Duration
+	AuthToken   string
+	Model       string
+	ModelSource string
+	Models      config.ModelMap
+	Env         map[string]string
+	ClaudeArgs  []string
+	Project     *config.ProjectConfig
+
+	allow      []string
+	deny       []string
+	credential func() (string, error)
+}
+
+// TokenError is a failure to get or prepare the session's token, as opposed
+// to a config, profile or model error.
+type TokenError struct {
+	Err error
+}
+
+func (e *TokenError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *TokenError) Unwrap() error {
+	return e.Err
+}
+
+func New(opts Options) (*Session, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	project, err := config.LoadProject()
+	if err != nil {
+		return nil, err
+	}
+
+	profileName := opts.Profile
+	if profileName == "" {
+		profileName = project.Profile
+	}
+
+	profile, err := cfg.Profile(profileName)
+	if err != nil {
+		return nil, err
+	}
+
+	authToken, tokenSource, err := resolveToken(profile, opts.TokenName, opts.NoPrompt)
+	if err != nil {
+		return nil, &TokenError{fmt.Errorf("failed to get authentication token: %w", err)}
+	}
+
+	authMode := cfg.AuthModeFor(profile)
+	credential := func() (string, error) { return authToken, nil }
+	var authTTL time.Duration
+	if authMode == config.AuthModeJWT {
+		authTTL = cfg.JWTLifetime()
+		credential = token.JWTSource(authToken, authTTL)
+	}
+
+	childToken, err := credential()
+	if err != nil {
+		return nil, &TokenError{fmt.Errorf("failed to prepare authentication token from %s: %v", tokenSource, err)}
+	}
+
+	model, source := resolveModel(opts.Model, project, profile, cfg)
+	models := resolveModelMap(model, opts.Models, project.Models, cfg.Models)
+	for _, name := range []string{model, models.SmallFast, models.Opus, models.Sonnet, models.Haiku} {
+		if err := config.ValidateModel(name); err != nil {
+			return nil, err
+		}
+	}
+
+	env := make(map[string]string, len(profile.Env)+len(project.Env))
+	for key, value := range profile.Env {
+		env[key] = value
+	}
+	for key, value := range project.Env {
+		env[key] = value
+	}
+
+	return &Session{
+		Profile:     profile.Name,
+		BaseURL:     profile.BaseURL,
+		Token:       authToken,
+		TokenSource: tokenSource,
+		AuthMode:    authMode,
+		AuthTTL:     authTTL,
+		AuthToken:   childToken,
+		Model:       model,
+		ModelSource: source,
+		Models:      models,
+		Env:         env,
+		ClaudeArgs:  project.ClaudeArgs,
+		Project:     project,
+		allow:       cfg.EnvAllow,
+		deny:        cfg.EnvDeny,
+		credential:  credential,
+	}, nil
+}
+
+func resolveModel(flag string, project *config.ProjectConfig, profile *config.Profile, cfg *config.Config) (string, string) {
+	switch {
+	case flag != "":
+		return flag, "--model flag"
+	case os.Getenv("GLM_MODEL") != "":
+		return os.Getenv("GLM_MODEL"), "GLM_MODEL"
+	case project.Model != "":
+		return project.Model, project.Path
+	case profile.DefaultModel != "":
+		return profile.DefaultModel, "profile " + profile.Name
+	case cfg.DefaultModel != "":
+		return cfg.DefaultModel, "default_model"
+	default:
+		return config.BuiltinModel, "built-in default"
+	}
+}
+
+func resolveModelMap(model string, overrides, project, configured config.ModelMap) config.ModelMap {
+	pick := func(candidates ...string) string {
+		for _, candidate := range candidates {
+			if candidate != "" {
+				return candidate
+			}
+		}
+		return ""
+	}
+
+	return config.ModelMap{
+		SmallFast: pick(overrides.SmallFast, project.SmallFast, configured.SmallFast, config.BuiltinFastModel),
+		Opus:      pick(overrides.Opus, project.Opus, configured.Opus, model),
+		Sonnet:    pick(overrides.Sonnet, project.Sonnet, configured.Sonnet, model),
+		Haiku:     pick(overrides.Haiku, project.Haiku, configured.Haiku, config.BuiltinFastModel),
+	}
+}
+
+func resolveToken(profile *config.Profile, name string, noPrompt bool) (string, string, error) {
+	if name == "" && profile.TokenEnv != "" {
+		if value := os.Getenv(profile.TokenEnv); value != "" {
+			return value, "$" + profile.TokenEnv, nil
+		}
+		return "", "", fmt.Errorf("profile %q reads its token from $%s, which is not set", profile.Name, profile.TokenEnv)
+	}
+
+	if name == "" {
+		name = profile.TokenName
+	}
+
+	if noPrompt {
+		value, source, err := token.Lookup(name)
+		if err == nil && value == "" {
+			err = fmt.Errorf("no token configured. Use 'glm token set' to configure it")
+		}
+		return value, source, err
+	}
+
+	return token.Get(name)
+}
+
+// Credential returns the function that produces the upstream token: the
+// stored key itself, or a fresh JWT in jwt auth mode.
+func (s *Session) Credential() func() (string, error) {
+	return s.credential
+}
+
+func (s *Session) Vars() map[string]string {
+	vars := make(map[string]string, len(s.Env)+7)
+	for key, value := range s.Env {
+		vars[key] = value
+	}
+
+	vars["ANTHROPIC_BASE_URL"] = s.BaseURL
+	vars["ANTHROPIC_AUTH_TOKEN"] = s.AuthToken
+	vars["ANTHROPIC_MODEL"] = s.Model
+	vars["ANTHROPIC_SMALL_FAST_MODEL"] = s.Models.SmallFast
+	vars["ANTHROPIC_DEFAULT_OPUS_MODEL"] = s.Models.Opus
+	vars["ANTHROPIC_DEFAULT_SONNET_MODEL"] = s.Models.Sonnet
+	vars["ANTHROPIC_DEFAULT_HAIKU_MODEL"] = s.Models.Haiku
+
+	return vars
+}
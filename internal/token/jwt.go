@@ -0,0 +1,79 @@
+package token
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+type jwtHeader struct {
+	Alg      string `json:"alg"`
+	SignType string `json:"sign_type"`
+}
+
+type jwtClaims struct {
+	APIKey    string `json:"api_key"`
+	Exp       int64  `json:"exp"`
+	Timestamp int64  `json:"timestamp"`
+}
+
+// MintJWT signs a BigModel API key of the form {id}.{secret} into an HS256
+// JWT valid for ttl. Both timestamps are in milliseconds, as the platform
+// expects.
+func MintJWT(apiKey string, ttl time.Duration, now time.Time) (string, time.Time, error) {
+	id, secret, ok := strings.Cut(apiKey, ".")
+	if !ok || id == "" || secret == "" || strings.Contains(secret, ".") {
+		return "", time.Time{}, fmt.Errorf("JWT auth requires a BigModel API key of the form {id}.{secret}")
+	}
+
+	expires := now.Add(ttl)
+
+	header, err := json.Marshal(jwtHeader{Alg: "HS256", SignType: "SIGN"})
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	claims, err := json.Marshal(jwtClaims{APIKey: id, Exp: expires.UnixMilli(), Timestamp: now.UnixMilli()})
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(signingInput))
+	signature := base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+
+	return signingInput + "." + signature, expires, nil
+}
+
+// JWTSource returns a function that hands out a JWT for apiKey, minting a
+// new one once less than a fifth of its lifetime remains.
+func JWTSource(apiKey string, ttl time.Duration) func() (string, error) {
+	var (
+		mu      sync.Mutex
+		current string
+		expires time.Time
+	)
+
+	return func() (string, error) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		now := time.Now()
+		if current != "" && expires.Sub(now) > ttl/5 {
+			return current, nil
+		}
+
+		jwt, exp, err := MintJWT(apiKey, ttl, now)
+		if err != nil {
+			return "", err
+		}
+		current, expires = jwt, exp
+		return current, nil
+	}
+}
@@ -0,0 +1,90 @@
+package token
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const commandTimeout = 30 * time.Second
+
+var (
+	commandMu    sync.Mutex
+	commandCache = make(map[string]string)
+)
+
+// runTokenCommand runs a secret manager command such as
+// ["pass", "show", "bigmodel"] and returns the first line it prints. The
+// result is cached for the life of the process.
+func runTokenCommand(argv []string) (string, error) {
+	display := strings.Join(argv, " ")
+	cacheKey := strings.Join(argv, "\x00")
+
+	commandMu.Lock()
+	defer commandMu.Unlock()
+
+	if token, ok := commandCache[cacheKey]; ok {
+		return token, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), commandTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, argv[0], argv[1:]...)
+	cmd.Stdin = os.Stdin
+	cmd.Stderr = os.Stderr
+
+	out, err := cmd.Output()
+	if ctx.Err() == context.DeadlineExceeded {
+		return "", fmt.Errorf("token command %q timed out after %s", display, commandTimeout)
+	}
+
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return "", fmt.Errorf("token command %q failed with exit code %d", display, exitErr.ExitCode())
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to run token command %q: %v", display, err)
+	}
+
+	token := firstLine(string(out))
+	if token == "" {
+		return "", fmt.Errorf("token command %q printed no token", display)
+	}
+
+	commandCache[cacheKey] = token
+	return token, nil
+}
+
+func readTokenFile(path string) (string, error) {
+	if rest, ok := strings.CutPrefix(path, "~/"); ok {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to expand %s: %v", path, err)
+		}
+		path = filepath.Join(home, rest)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read token file: %v", err)
+	}
+
+	token := firstLine(string(data))
+	if token == "" {
+		return "", fmt.Errorf("token file %s is empty", path)
+	}
+	return token, nil
+}
+
+func firstLine(s string) string {
+	s = strings.TrimSpace(s)
+	line, _, _ := strings.Cut(s, "\n")
+	return strings.TrimSpace(line)
+}
@@ -7,24 +7,71 @@ import (
 	"syscall"
 
 	"github.com/xqsit94/glm/internal/config"
+	"github.com/xqsit94/glm/internal/vault"
 	"github.com/xqsit94/glm/pkg/paths"
 
 	"golang.org/x/term"
 )
 
-const defaultModel = "glm-4.6"
+// readSecret reads a line from the terminal without echoing it.
+var readSecret = func() ([]byte, error) {
+	return term.ReadPassword(int(syscall.Stdin))
+}
+
+// Lookup returns the token called name, or when name is empty the
+// ANTHROPIC_AUTH_TOKEN environment variable, token_command, token_file and
+// finally the active stored token.
+// Encrypted tokens are only unlocked without prompting.
+func Lookup(name string) (string, string, error) {
+	return lookup(name, false)
+}
 
-func Get() (string, error) {
-	if token := os.Getenv("ANTHROPIC_AUTH_TOKEN"); token != "" {
-		return token, nil
+func lookup(name string, prompt bool) (string, string, error) {
+	if name == "" {
+		if token := os.Getenv("ANTHROPIC_AUTH_TOKEN"); token != "" {
+			return token, "ANTHROPIC_AUTH_TOKEN environment variable", nil
+		}
 	}
 
 	cfg, err := config.Load()
 	if err != nil {
-		return "", err
+		return "", "", err
+	}
+
+	explicit := name != ""
+	if !explicit {
+		if len(cfg.TokenCommand) > 0 {
+			token, err := runTokenCommand(cfg.TokenCommand)
+			return token, "token_command (" + strings.Join(cfg.TokenCommand, " ") + ")", err
+		}
+		if cfg.TokenFile != "" {
+			token, err := readTokenFile(cfg.TokenFile)
+			return token, "token_file " + cfg.TokenFile, err
+		}
+		name = cfg.ActiveTokenName()
+	}
+
+	value, ok := cfg.Token(name)
+	if !ok {
+		if explicit {
+			return "", "", fmt.Errorf("token %q not found. Run 'glm token list' to see stored tokens", name)
+		}
+		return "", "", nil
+	}
+
+	token, err := reveal(cfg, name, value, prompt)
+	if err != nil {
+		return "", "", err
 	}
-	if cfg.AnthropicAuthToken != "" {
-		return cfg.AnthropicAuthToken, nil
+	return token, fmt.Sprintf("token %q in %s", name, paths.GetConfigPath()), nil
+}
+
+// Get is like Lookup but may prompt to unlock encrypted tokens or to set up
+// a missing token.
+func Get(name string) (string, string, error) {
+	token, source, err := lookup(name, true)
+	if err != nil || token != "" {
+		return token, source, err
 	}
 
 	fmt.Println("🔐 No authentication token found.")
@@ -34,19 +81,52 @@ func Get() (string, error) {
 	fmt.Scanln(&response)
 
 	if strings.ToLower(response) == "y" || strings.ToLower(response) == "yes" {
-		if err := Set(); err != nil {
-			return "", err
+		if err := Set(SetOptions{Name: name}); err != nil {
+			return "", "", err
 		}
-		return Get()
+		return Get(name)
 	}
 
-	return "", fmt.Errorf("authentication token is required. Use 'glm token set' to configure it")
+	return "", "", fmt.Errorf("authentication token is required. Use 'glm token set' to configure it")
 }
 
-func Set() error {
-	fmt.Print("Enter your Anthropic API token: ")
+type SetOptions struct {
+	Name     string
+	Profile  string
+	NoVerify bool
+}
+
+// Set prompts for a token and stores it under opts.Name, or under the active
+// token name when it is empty. The first stored token becomes active. Unless
+// opts.NoVerify is set, a token the API rejects is not saved.
+func Set(opts SetOptions) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+
+	name := opts.Name
+
+	if name == "" {
+		name = cfg.ActiveTokenName()
+	}
+	if err := config.ValidateTokenName(name); err != nil {
+		return err
+	}
+
+	if cfg.Vault != nil {
+		if _, err := vaultKey(cfg, true); err != nil {
+			return err
+		}
+	}
+
+	if name == config.DefaultTokenName {
+		fmt.Print("Enter your Anthropic API token: ")
+	} else {
+		fmt.Printf("Enter the Anthropic API token for %q: ", name)
+	}
 
-	tokenBytes, err := term.ReadPassword(int(syscall.Stdin))
+	tokenBytes, err := readSecret()
 	if err != nil {
 		return fmt.Errorf("failed to read token: %v", err)
 	}
@@ -57,58 +137,174 @@ func Set() error {
 		return fmt.Errorf("token cannot be empty")
 	}
 
+	if !opts.NoVerify {
+		if err := checkFormat(tokenStr); err != nil {
+			return fmt.Errorf("%v. Use --no-verify to save it anyway", err)
+		}
+
+		p, err := verifyProfile(cfg, name, opts.Profile)
+		if err != nil {
+			return err
+		}
+		if verdict := verify(cfg, p, tokenStr); isInvalid(verdict) {
+			return fmt.Errorf("token was not saved. Use --no-verify to save it anyway")
+		}
+	}
+
+	stored, err := seal(cfg, name, tokenStr)
+	if err != nil {
+		return err
+	}
+
+	cfg.SetToken(name, stored)
+	if cfg.ActiveToken == "" {
+		cfg.ActiveToken = name
+	}
+
+	if err := config.Save(cfg); err != nil {
+		return err
+	}
+
+	fmt.Printf("✅ Token %q has been saved successfully!\n", name)
+	return nil
+}
+
+func List() error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+
+	names := cfg.TokenNames()
+	if len(names) == 0 {
+		fmt.Println("No tokens stored. Use 'glm token set' to add one.")
+		return nil
+	}
+
+	active := cfg.ActiveTokenName()
+	for _, name := range names {
+		marker := "  "
+		if name == active {
+			marker = "* "
+		}
+
+		value, _ := cfg.Token(name)
+		line := fmt.Sprintf("%s%-12s %s", marker, name, config.MaskSecret(value))
+
+		var usedBy []string
+		for _, profileName := range cfg.ProfileNames() {
+			if p, ok := cfg.Profiles[profileName]; ok && p.TokenName == name {
+				usedBy = append(usedBy, profileName)
+			}
+		}
+		if len(usedBy) > 0 {
+			line += "  (profiles: " + strings.Join(usedBy, ", ") + ")"
+		}
+
+		fmt.Println(line)
+	}
+
+	return nil
+}
+
+func Use(name string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+
+	if _, ok := cfg.Token(name); !ok {
+		return fmt.Errorf("token %q not found. Run 'glm token list' to see stored tokens", name)
+	}
+
+	cfg.ActiveToken = name
+	if err := config.Save(cfg); err != nil {
+		return err
+	}
+
+	fmt.Printf("✅ Now using token %q\n", name)
+	return nil
+}
+
+func Remove(name string) error {
 	cfg, err := config.Load()
 	if err != nil {
 		return err
 	}
 
-	cfg.AnthropicAuthToken = tokenStr
-	if cfg.DefaultModel == "" {
-		cfg.DefaultModel = defaultModel
+	for profileName, p := range cfg.Profiles {
+		if p.TokenName == name {
+			return fmt.Errorf("token %q is used by profile %q. Update or remove the profile first", name, profileName)
+		}
+	}
+
+	if !cfg.RemoveToken(name) {
+		return fmt.Errorf("token %q not found. Run 'glm token list' to see stored tokens", name)
 	}
 
 	if err := config.Save(cfg); err != nil {
 		return err
 	}
 
-	fmt.Println("✅ Authentication token has been saved successfully!")
+	fmt.Printf("✅ Token %q has been removed\n", name)
+	if cfg.ActiveToken == "" && len(cfg.Tokens) > 0 {
+		fmt.Println("💡 No active token. Run 'glm token use <name>' to pick one.")
+	}
 	return nil
 }
 
 func Show() error {
-	token, err := Get()
+	token, source, err := Get("")
 	if err != nil {
 		return err
 	}
 
-	// Mask token: show first 4 and last 4 chars
-	if len(token) > 8 {
-		masked := token[:4] + strings.Repeat("*", len(token)-8) + token[len(token)-4:]
-		fmt.Printf("Current token: %s\n", masked)
-	} else {
-		fmt.Println("Current token: ****")
+	fmt.Printf("Current token: %s\n", config.MaskSecret(token))
+	fmt.Printf("Source: %s\n", source)
+
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	if cfg.Vault != nil {
+		fmt.Println("Stored tokens are encrypted.")
+	}
+	if len(cfg.Tokens) > 1 {
+		fmt.Println()
+		fmt.Println("Stored tokens:")
+		return List()
 	}
 
 	return nil
 }
 
 func Clear() error {
-	configPath := paths.GetConfigPath()
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
 
-	if _, err := os.Stat(configPath); os.IsNotExist(err) {
+	if len(cfg.Tokens) == 0 {
 		fmt.Println("No token found to clear.")
 		return nil
 	}
 
-	if err := os.Remove(configPath); err != nil {
-		return fmt.Errorf("failed to remove config file: %v", err)
+	for profileName, p := range cfg.Profiles {
+		if p.TokenName != "" {
+			p.TokenName = ""
+			cfg.Profiles[profileName] = p
+		}
 	}
+	cfg.Tokens = nil
+	cfg.ActiveToken = ""
+	cfg.Vault = nil
 
-	configDir := paths.GetConfigDir()
-	if entries, err := os.ReadDir(configDir); err == nil && len(entries) == 0 {
-		os.Remove(configDir)
+	if err := config.Save(cfg); err != nil {
+		return err
 	}
 
-	fmt.Println("✅ Authentication token has been cleared successfully!")
+	vault.Stop(paths.GetAgentSocketPath())
+
+	fmt.Println("✅ All stored tokens have been cleared successfully!")
 	return nil
 }
@@ -0,0 +1,299 @@
+package token
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/xqsit94/glm/internal/config"
+	"github.com/xqsit94/glm/internal/vault"
+	"github.com/xqsit94/glm/pkg/paths"
+
+	"golang.org/x/term"
+)
+
+const (
+	PassphraseEnv    = "GLM_PASSPHRASE"
+	DefaultUnlockTTL = 15 * time.Minute
+)
+
+var ErrLocked = errors.New("tokens are encrypted and locked. Run 'glm token unlock' or set GLM_PASSPHRASE")
+
+// unlockedKey keeps the vault key for the rest of this process so a command
+// never asks for the passphrase twice.
+var unlockedKey []byte
+
+func vaultKey(cfg *config.Config, prompt bool) ([]byte, error) {
+	if unlockedKey != nil && vault.Verify(cfg.Vault, unlockedKey) == nil {
+		return unlockedKey, nil
+	}
+
+	if key, err := vault.FetchKey(paths.GetAgentSocketPath()); err == nil && vault.Verify(cfg.Vault, key) == nil {
+		unlockedKey = key
+		return key, nil
+	}
+
+	if passphrase := os.Getenv(PassphraseEnv); passphrase != "" {
+		key, err := vault.Unlock(cfg.Vault, passphrase)
+		if errors.Is(err, vault.ErrWrongPassphrase) {
+			return nil, fmt.Errorf("%s does not unlock the stored tokens", PassphraseEnv)
+		}
+		if err != nil {
+			return nil, err
+		}
+		unlockedKey = key
+		return key, nil
+	}
+
+	if !prompt || !term.IsTerminal(int(syscall.Stdin)) {
+		return nil, ErrLocked
+	}
+
+	for attempt := 0; attempt < 3; attempt++ {
+		passphrase, err := readPassphrase("🔒 Enter passphrase to unlock your tokens: ")
+		if err != nil {
+			return nil, err
+		}
+
+		key, err := vault.Unlock(cfg.Vault, passphrase)
+		if errors.Is(err, vault.ErrWrongPassphrase) {
+			fmt.Fprintln(os.Stderr, "❌ Wrong passphrase.")
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		unlockedKey = key
+		return key, nil
+	}
+
+	return nil, fmt.Errorf("failed to unlock tokens: %v", vault.ErrWrongPassphrase)
+}
+
+// readPassphrase prompts on stderr so commands like 'glm env' keep a clean
+// stdout.
+func readPassphrase(prompt string) (string, error) {
+	fmt.Fprint(os.Stderr, prompt)
+	passphrase, err := term.ReadPassword(int(syscall.Stdin))
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", fmt.Errorf("failed to read passphrase: %v", err)
+	}
+	return string(passphrase), nil
+}
+
+func newPassphrase() (string, error) {
+	if passphrase := os.Getenv(PassphraseEnv); passphrase != "" {
+		return passphrase, nil
+	}
+
+	if !term.IsTerminal(int(syscall.Stdin)) {
+		return "", fmt.Errorf("a passphrase is required. Run this command in a terminal or set %s", PassphraseEnv)
+	}
+
+	passphrase, err := readPassphrase("Enter a new passphrase: ")
+	if err != nil {
+		return "", err
+	}
+	if passphrase == "" {
+		return "", fmt.Errorf("passphrase cannot be empty")
+	}
+
+	confirm, err := readPassphrase("Confirm passphrase: ")
+	if err != nil {
+		return "", err
+	}
+	if confirm != passphrase {
+		return "", fmt.Errorf("passphrases do not match")
+	}
+
+	return passphrase, nil
+}
+
+// reveal returns the plaintext of a stored token value, unlocking the vault
+// when the value is encrypted.
+func reveal(cfg *config.Config, name, value string, prompt bool) (string, error) {
+	if !config.IsEncryptedToken(value) {
+		return value, nil
+	}
+	if cfg.Vault == nil {
+		return "", fmt.Errorf("token %q is encrypted but the config has no vault settings", name)
+	}
+
+	key, err := vaultKey(cfg, prompt)
+	if err != nil {
+		return "", err
+	}
+	return vault.Decrypt(key, name, value)
+}
+
+// seal returns the value to store for a token, encrypting it when the vault
+// is enabled.
+func seal(cfg *config.Config, name, value string) (string, error) {
+	if cfg.Vault == nil {
+		return value, nil
+	}
+
+	key, err := vaultKey(cfg, true)
+	if err != nil {
+		return "", err
+	}
+	return vault.Encrypt(key, name, value)
+}
+
+func Encrypt() error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+
+	if cfg.Vault != nil {
+		return fmt.Errorf("tokens are already encrypted")
+	}
+
+	passphrase, err := newPassphrase()
+	if err != nil {
+		return err
+	}
+
+	v, key, err := vault.New(passphrase)
+	if err != nil {
+		return err
+	}
+
+	for name, value := range cfg.Tokens {
+		encrypted, err := vault.Encrypt(key, name, value)
+		if err != nil {
+			return err
+		}
+		cfg.Tokens[name] = encrypted
+	}
+	cfg.Vault = v
+
+	if err := config.Save(cfg); err != nil {
+		return err
+	}
+
+	fmt.Printf("🔒 Encrypted %d token(s) in %s\n", len(cfg.Tokens), paths.GetConfigPath())
+	fmt.Println("💡 Older plaintext copies may still exist in backups or dotfile repositories; rotate those tokens if needed.")
+	fmt.Println("💡 Run 'glm token unlock' to avoid typing the passphrase for a while.")
+	return nil
+}
+
+func Decrypt() error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+
+	if cfg.Vault == nil {
+		return fmt.Errorf("tokens are not encrypted")
+	}
+
+	key, err := vaultKey(cfg, true)
+	if err != nil {
+		return err
+	}
+
+	for name, value := range cfg.Tokens {
+		plaintext, err := vault.Decrypt(key, name, value)
+		if err != nil {
+			return err
+		}
+		cfg.Tokens[name] = plaintext
+	}
+	cfg.Vault = nil
+
+	if err := config.Save(cfg); err != nil {
+		return err
+	}
+	vault.Stop(paths.GetAgentSocketPath())
+
+	fmt.Printf("🔓 Decrypted %d token(s); they are now stored in plaintext in %s\n", len(cfg.Tokens), paths.GetConfigPath())
+	return nil
+}
+
+func Unlock(ttl time.Duration) error {
+	if ttl <= 0 {
+		return fmt.Errorf("--ttl must be positive")
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+
+	if cfg.Vault == nil {
+		return fmt.Errorf("tokens are not encrypted. Run 'glm token encrypt' first")
+	}
+
+	key, err := vaultKey(cfg, true)
+	if err != nil {
+		return err
+	}
+
+	if err := startAgent(key, ttl); err != nil {
+		return err
+	}
+
+	fmt.Printf("🔓 Tokens unlocked for %s\n", ttl)
+	return nil
+}
+
+func Lock() error {
+	if vault.Stop(paths.GetAgentSocketPath()) {
+		fmt.Println("🔒 Tokens locked")
+	} else {
+		fmt.Println("No unlock agent is running.")
+	}
+	return nil
+}
+
+func startAgent(key []byte, ttl time.Duration) error {
+	exe, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to locate glm executable: %v", err)
+	}
+
+	cmd := exec.Command(exe, "token", "agent", "--ttl", ttl.String())
+	cmd.Stdin = strings.NewReader(hex.EncodeToString(key) + "\n")
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start unlock agent: %v", err)
+	}
+
+	socketPath := paths.GetAgentSocketPath()
+	for deadline := time.Now().Add(3 * time.Second); time.Now().Before(deadline); time.Sleep(50 * time.Millisecond) {
+		if cached, err := vault.FetchKey(socketPath); err == nil && bytes.Equal(cached, key) {
+			return cmd.Process.Release()
+		}
+	}
+
+	cmd.Process.Kill()
+	return fmt.Errorf("unlock agent did not start")
+}
+
+// RunAgent serves the key read from stdin on the agent socket. It is the
+// body of the hidden 'glm token agent' command started by Unlock.
+func RunAgent(ttl time.Duration) error {
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil {
+		return fmt.Errorf("failed to read key: %v", err)
+	}
+
+	key, err := hex.DecodeString(strings.TrimSpace(line))
+	if err != nil {
+		return fmt.Errorf("invalid key: %v", err)
+	}
+
+	signal.Ignore(syscall.SIGHUP)
+
+	return vault.Serve(paths.GetAgentSocketPath(), key, ttl)
+}
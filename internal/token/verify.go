@@ -0,0 +1,140 @@
+package token
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/xqsit94/glm/internal/api"
+	"github.com/xqsit94/glm/internal/config"
+)
+
+// verifyProfile picks the profile a token is checked against: the one asked
+// for, else the first profile pinned to the token, else the active profile.
+func verifyProfile(cfg *config.Config, name, profileName string) (*config.Profile, error) {
+	if profileName == "" && name != "" {
+		for _, candidate := range cfg.ProfileNames() {
+			if p, ok := cfg.Profiles[candidate]; ok && p.TokenName == name {
+				profileName = candidate
+				break
+			}
+		}
+	}
+	return cfg.Profile(profileName)
+}
+
+func checkFormat(value string) error {
+	if strings.ContainsAny(value, "\"'` \t\r\n") {
+		return fmt.Errorf("the token contains quotes or whitespace; check for a copy/paste mistake")
+	}
+	return nil
+}
+
+func probe(cfg *config.Config, p *config.Profile, value string) *api.ProbeResult {
+	credential := value
+	if cfg.AuthModeFor(p) == config.AuthModeJWT {
+		jwt, err := JWTSource(value, cfg.JWTLifetime())()
+		if err != nil {
+			return &api.ProbeResult{Err: err}
+		}
+		credential = jwt
+	}
+
+	model := p.DefaultModel
+	if model == "" {
+		model = cfg.DefaultModel
+	}
+	if model == "" {
+		model = config.BuiltinModel
+	}
+
+	return api.Probe(context.Background(), p.BaseURL, credential, model)
+}
+
+// verify checks value against p with a one-token request, prints the
+// outcome and returns its classification.
+func verify(cfg *config.Config, p *config.Profile, value string) api.Verdict {
+	target := fmt.Sprintf("%s (profile %s)", p.BaseURL, p.Name)
+	fmt.Printf("🔎 Verifying token against %s...\n", target)
+
+	result := probe(cfg, p, value)
+	verdict := result.Verdict()
+
+	switch verdict {
+	case api.VerdictValid:
+		fmt.Printf("✅ Token is valid (%dms)\n", result.Latency.Milliseconds())
+	case api.VerdictRateLimited:
+		fmt.Printf("⚠️  %s is rate limiting requests (HTTP 429); the token was recognised\n", p.BaseURL)
+	case api.VerdictNetworkError:
+		fmt.Printf("⚠️  Could not reach %s: %v\n", p.BaseURL, result.Err)
+	case api.VerdictWrongEndpoint:
+		fmt.Printf("❌ %s does not look like an Anthropic-compatible endpoint (HTTP %d)\n", p.BaseURL, result.StatusCode)
+	case api.VerdictUnauthorized:
+		if other := otherRegion(cfg, p, value); other != "" {
+			fmt.Printf("❌ Token is not valid for profile %s, but profile %s accepts it (wrong region)\n", p.Name, other)
+			fmt.Printf("💡 Use --profile %s, or run 'glm profile use %s'\n", other, other)
+			return api.VerdictWrongEndpoint
+		}
+		fmt.Printf("❌ Token was rejected (HTTP %d) %s\n", result.StatusCode, result.Message)
+	default:
+		fmt.Printf("⚠️  %s answered HTTP %d %s\n", p.BaseURL, result.StatusCode, result.Message)
+	}
+
+	return verdict
+}
+
+// otherRegion returns the built-in profile that accepts value when the
+// built-in profile p does not, which means the key belongs to the other
+// platform. Keys for custom profiles are never sent elsewhere.
+func otherRegion(cfg *config.Config, p *config.Profile, value string) string {
+	if _, overridden := cfg.Profiles[p.Name]; overridden || !config.IsBuiltinProfile(p.Name) {
+		return ""
+	}
+
+	for _, name := range cfg.ProfileNames() {
+		if _, overridden := cfg.Profiles[name]; overridden || name == p.Name || !config.IsBuiltinProfile(name) {
+			continue
+		}
+		other, err := cfg.Profile(name)
+		if err != nil {
+			continue
+		}
+		switch probe(cfg, other, value).Verdict() {
+		case api.VerdictValid, api.VerdictRateLimited:
+			return name
+		}
+	}
+	return ""
+}
+
+func isInvalid(verdict api.Verdict) bool {
+	return verdict == api.VerdictUnauthorized || verdict == api.VerdictWrongEndpoint
+}
+
+// Verify checks a stored or configured token against the API.
+func Verify(name, profileName string) error {
+	value, source, err := Get(name)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+
+	p, err := verifyProfile(cfg, name, profileName)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Token: %s (from %s)\n", config.MaskSecret(value), source)
+	if err := checkFormat(value); err != nil {
+		return err
+	}
+
+	if verdict := verify(cfg, p, value); verdict != api.VerdictValid && verdict != api.VerdictRateLimited {
+		return fmt.Errorf("token verification failed: %s", verdict)
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+package token
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/xqsit94/glm/internal/config"
+)
+
+// withAPI points a fresh config at a fake API that answers every request
+// with status and returns the number of requests it received.
+func withAPI(t *testing.T, status int) *atomic.Int32 {
+	t.Helper()
+	t.Setenv("GLM_HOME", t.TempDir())
+	t.Setenv("ANTHROPIC_AUTH_TOKEN", "")
+
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		w.Write([]byte(`{"error":{"message":"test"}}`))
+	}))
+	t.Cleanup(server.Close)
+
+	cfg := &config.Config{
+		ActiveProfile: "test",
+		Profiles:      map[string]config.Profile{"test": {BaseURL: server.URL}},
+	}
+	if err := config.Save(cfg); err != nil {
+		t.Fatal(err)
+	}
+	return &requests
+}
+
+func withInput(t *testing.T, value string) {
+	t.Helper()
+	original := readSecret
+	readSecret = func() ([]byte, error) { return []byte(value), nil }
+	t.Cleanup(func() { readSecret = original })
+}
+
+func TestSetRefusesRejectedToken(t *testing.T) {
+	for _, status := range []int{http.StatusUnauthorized, http.StatusNotFound} {
+		requests := withAPI(t, status)
+		withInput(t, "bad.secret")
+
+		if err := Set(SetOptions{}); err == nil {
+			t.Fatalf("status %d: Set saved a rejected token", status)
+		}
+		if requests.Load() != 1 {
+			t.Errorf("status %d: expected 1 probe, got %d", status, requests.Load())
+		}
+
+		cfg, err := config.Load()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(cfg.Tokens) != 0 {
+			t.Errorf("status %d: tokens were saved: %v", status, cfg.TokenNames())
+		}
+	}
+}
+
+func TestSetSavesAcceptedToken(t *testing.T) {
+	for _, status := range []int{http.StatusOK, http.StatusTooManyRequests} {
+		withAPI(t, status)
+		withInput(t, "good.secret")
+
+		if err := Set(SetOptions{Name: "work"}); err != nil {
+			t.Fatalf("status %d: %v", status, err)
+		}
+
+		cfg, err := config.Load()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if value, _ := cfg.Token("work"); value != "good.secret" {
+			t.Errorf("status %d: stored token = %q", status, value)
+		}
+		if cfg.ActiveToken != "work" {
+			t.Errorf("status %d: active token = %q, want work", status, cfg.ActiveToken)
+		}
+	}
+}
+
+func TestSetNoVerifySkipsProbe(t *testing.T) {
+	requests := withAPI(t, http.StatusUnauthorized)
+	withInput(t, "bad.secret")
+
+	if err := Set(SetOptions{NoVerify: true}); err != nil {
+		t.Fatal(err)
+	}
+	if requests.Load() != 0 {
+		t.Errorf("--no-verify still sent %d requests", requests.Load())
+	}
+}
+
+func TestSetRejectsMalformedToken(t *testing.T) {
+	requests := withAPI(t, http.StatusOK)
+	withInput(t, `"abc.def"`)
+
+	if err := Set(SetOptions{}); err == nil {
+		t.Fatal("Set accepted a quoted token")
+	}
+	if requests.Load() != 0 {
+		t.Errorf("a malformed token was sent to the API")
+	}
+}
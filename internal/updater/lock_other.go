@@ -0,0 +1,15 @@
+//go:build !unix
+
+package updater
+
+import (
+	"os"
+)
+
+func lockFile(f *os.File) error {
+	return nil
+}
+
+func unlockFile(f *os.File) {}
+
+func preserveOwner(path string, info os.FileInfo) {}
@@ -0,0 +1,161 @@
+package updater
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/xqsit94/glm/pkg/paths"
+)
+
+const lockFileName = ".glm-update.lock"
+
+// createStagingFile creates the download file next to the installed binary
+// so the final rename never crosses filesystems, falling back to the system
+// temp directory when that directory is not writable.
+func createStagingFile() (*os.File, error) {
+	if current, err := currentExecutable(); err == nil {
+		if f, err := os.CreateTemp(filepath.Dir(current), ".glm-update-*"); err == nil {
+			return f, nil
+		}
+	}
+	return os.CreateTemp("", "glm-update-*")
+}
+
+// stageFile copies src into a new hidden file in dir and flushes it to disk.
+func stageFile(src, dir string, mode os.FileMode) (string, error) {
+	in, err := os.Open(src)
+	if err != nil {
+		return "", err
+	}
+	defer in.Close()
+
+	out, err := os.CreateTemp(dir, ".glm-update-*")
+	if err != nil {
+		return "", err
+	}
+
+	_, err = io.Copy(out, in)
+	if err == nil {
+		err = out.Sync()
+	}
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chmod(out.Name(), mode)
+	}
+	if err != nil {
+		os.Remove(out.Name())
+		return "", err
+	}
+
+	return out.Name(), nil
+}
+
+func copyFile(src, dst string, mode os.FileMode) error {
+	staged, err := stageFile(src, filepath.Dir(dst), mode)
+	if err != nil {
+		return err
+	}
+	if err := os.Rename(staged, dst); err != nil {
+		os.Remove(staged)
+		return err
+	}
+	return nil
+}
+
+// replaceExecutable atomically puts newBinaryPath in place of the running
+// binary, keeping its mode and, where permitted, its owner. A file on another
+// filesystem is first copied next to the binary, since rename cannot cross
+// mounts.
+func replaceExecutable(newBinaryPath string) error {
+	currentBinary, err := currentExecutable()
+	if err != nil {
+		return err
+	}
+
+	info, err := os.Stat(currentBinary)
+	if err != nil {
+		return fmt.Errorf("failed to stat current binary: %v", err)
+	}
+	dir := filepath.Dir(currentBinary)
+
+	staged := newBinaryPath
+	if filepath.Dir(newBinaryPath) != dir {
+		staged, err = stageFile(newBinaryPath, dir, info.Mode().Perm())
+		if err != nil {
+			return fmt.Errorf("failed to stage new binary in %s: %v", dir, err)
+		}
+		os.Remove(newBinaryPath)
+	}
+
+	if err := os.Chmod(staged, info.Mode().Perm()); err != nil {
+		os.Remove(staged)
+		return fmt.Errorf("failed to set binary permissions: %v", err)
+	}
+	preserveOwner(staged, info)
+
+	if err := os.Rename(staged, currentBinary); err != nil {
+		os.Remove(staged)
+		return fmt.Errorf("failed to install new binary: %v", err)
+	}
+
+	syncDir(dir)
+	return nil
+}
+
+func syncDir(dir string) {
+	if d, err := os.Open(dir); err == nil {
+		d.Sync()
+		d.Close()
+	}
+}
+
+// Lock takes an exclusive lock so concurrent 'glm update' runs cannot
+// interleave. Call the returned function to release it.
+func Lock() (func(), error) {
+	f, err := openLockFile()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := lockFile(f); err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	return func() {
+		unlockFile(f)
+		f.Close()
+	}, nil
+}
+
+// openLockFile opens the lock next to the installed binary, so that
+// 'sudo glm update' and runs as a normal user exclude each other. A lock
+// created by root can still be taken read-only. When the binary's directory
+// is not writable and has no lock yet, the state directory is used instead;
+// such a run cannot replace the binary anyway.
+func openLockFile() (*os.File, error) {
+	if binary, err := currentExecutable(); err == nil {
+		lockPath := filepath.Join(filepath.Dir(binary), lockFileName)
+		if f, err := os.OpenFile(lockPath, os.O_CREATE|os.O_RDWR, 0644); err == nil {
+			return f, nil
+		}
+		if f, err := os.Open(lockPath); err == nil {
+			return f, nil
+		}
+	}
+
+	if err := os.MkdirAll(paths.GetStateDir(), 0700); err != nil {
+		return nil, fmt.Errorf("failed to create state directory: %v", err)
+	}
+
+	lockPath := filepath.Join(paths.GetStateDir(), "update.lock")
+	f, err := os.OpenFile(lockPath, os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %v", lockPath, err)
+	}
+	return f, nil
+}
@@ -0,0 +1,88 @@
+package updater
+
+import (
+	"strconv"
+	"strings"
+)
+
+type semver struct {
+	core       [3]int
+	prerelease []string
+}
+
+// parseSemver reads "v1.2.3-rc.1+build.5". Build metadata is dropped, and
+// missing or non-numeric major, minor and patch numbers count as 0.
+func parseSemver(version string) semver {
+	version = strings.TrimPrefix(strings.TrimSpace(version), "v")
+	version, _, _ = strings.Cut(version, "+")
+
+	var v semver
+	core, prerelease, hasPrerelease := strings.Cut(version, "-")
+	if hasPrerelease {
+		v.prerelease = strings.Split(prerelease, ".")
+	}
+
+	for i, part := range strings.SplitN(core, ".", len(v.core)) {
+		if n, err := strconv.Atoi(part); err == nil && n >= 0 {
+			v.core[i] = n
+		}
+	}
+	return v
+}
+
+func isPrerelease(version string) bool {
+	return len(parseSemver(version).prerelease) > 0
+}
+
+// compare orders a and b by semver precedence: a release ranks above its
+// prereleases, numeric identifiers compare as numbers and rank below
+// alphanumeric ones.
+func (a semver) compare(b semver) int {
+	for i := range a.core {
+		if a.core[i] != b.core[i] {
+			return sign(a.core[i] - b.core[i])
+		}
+	}
+
+	switch {
+	case len(a.prerelease) == 0 && len(b.prerelease) == 0:
+		return 0
+	case len(a.prerelease) == 0:
+		return 1
+	case len(b.prerelease) == 0:
+		return -1
+	}
+
+	for i := 0; i < len(a.prerelease) && i < len(b.prerelease); i++ {
+		if c := compareIdentifier(a.prerelease[i], b.prerelease[i]); c != 0 {
+			return c
+		}
+	}
+	return sign(len(a.prerelease) - len(b.prerelease))
+}
+
+func compareIdentifier(a, b string) int {
+	an, aErr := strconv.Atoi(a)
+	bn, bErr := strconv.Atoi(b)
+	switch {
+	case aErr == nil && bErr == nil:
+		return sign(an - bn)
+	case aErr == nil:
+		return -1
+	case bErr == nil:
+		return 1
+	default:
+		return strings.Compare(a, b)
+	}
+}
+
+func sign(n int) int {
+	switch {
+	case n > 0:
+		return 1
+	case n < 0:
+		return -1
+	default:
+		return 0
+	}
+}
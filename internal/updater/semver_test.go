@@ -0,0 +1,110 @@
+package updater
+
+import (
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		current, latest string
+		want            int
+	}{
+		{"1.1.0", "1.2.0", 1},
+		{"1.2.0", "1.1.0", -1},
+		{"v1.2.0", "1.2.0", 0},
+		{"1.9.0", "1.10.0", 1},
+		{"1.2", "1.2.0", 0},
+		{"1", "1.0.1", 1},
+
+		// A release ranks above its prereleases.
+		{"1.2.0", "1.2.0-rc1", -1},
+		{"1.2.0-rc1", "1.2.0", 1},
+		{"1.1.0", "1.2.0-rc1", 1},
+		{"1.2.0-rc1", "1.2.0-rc1", 0},
+
+		// Build metadata is ignored.
+		{"1.2.0+build.5", "1.2.0", 0},
+		{"1.2.0", "v1.2.0+20261018", 0},
+		{"1.2.0-rc.1+a", "1.2.0-rc.1+b", 0},
+
+		// The precedence example from the semver spec.
+		{"1.0.0-alpha", "1.0.0-alpha.1", 1},
+		{"1.0.0-alpha.1", "1.0.0-alpha.beta", 1},
+		{"1.0.0-alpha.beta", "1.0.0-beta", 1},
+		{"1.0.0-beta", "1.0.0-beta.2", 1},
+		{"1.0.0-beta.2", "1.0.0-beta.11", 1},
+		{"1.0.0-beta.11", "1.0.0-rc.1", 1},
+		{"1.0.0-rc.1", "1.0.0", 1},
+
+		// Numeric identifiers rank below alphanumeric ones.
+		{"1.0.0-rc", "1.0.0-1", -1},
+	}
+
+	for _, tt := range tests {
+		if got := CompareVersions(tt.current, tt.latest); got != tt.want {
+			t.Errorf("CompareVersions(%q, %q) = %d, want %d", tt.current, tt.latest, got, tt.want)
+		}
+	}
+}
+
+type fakeSource []ReleaseInfo
+
+func (s fakeSource) Name() string                     { return "fake" }
+func (s fakeSource) Releases() ([]ReleaseInfo, error) { return s, nil }
+func (s fakeSource) Open(string, string) (io.ReadCloser, int64, error) {
+	return io.NopCloser(strings.NewReader("")), 0, nil
+}
+
+func TestListReleasesChannels(t *testing.T) {
+	source := fakeSource{
+		{TagName: "v1.1.0"},
+		{TagName: "v1.2.1-rc1"},
+		{TagName: "v1.3.0", Draft: true},
+		{TagName: "v1.2.0"},
+		{TagName: "v1.2.2-beta", Prerelease: true},
+	}
+
+	tests := []struct {
+		channel string
+		want    []string
+	}{
+		{ChannelStable, []string{"v1.2.0", "v1.1.0"}},
+		{ChannelBeta, []string{"v1.2.2-beta", "v1.2.1-rc1", "v1.2.0", "v1.1.0"}},
+	}
+
+	for _, tt := range tests {
+		releases, err := ListReleases(source, tt.channel)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for _, release := range releases {
+			got = append(got, release.TagName)
+		}
+		if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+			t.Errorf("%s channel: got %v, want %v", tt.channel, got, tt.want)
+		}
+	}
+
+	info, err := CheckForUpdate(source, "1.1.0", CheckOptions{Channel: ChannelStable})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.LatestVersion != "v1.2.0" || !info.HasUpdate {
+		t.Errorf("stable update = %s (has update %v), want v1.2.0", info.LatestVersion, info.HasUpdate)
+	}
+
+	info, err = CheckForUpdate(source, "1.2.0", CheckOptions{Version: "1.1.0"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !info.HasUpdate || !info.Downgrade {
+		t.Errorf("pinning an older version: has update %v, downgrade %v", info.HasUpdate, info.Downgrade)
+	}
+
+	if _, err := CheckForUpdate(source, "1.2.0", CheckOptions{Version: "v9.9.9"}); err == nil {
+		t.Error("pinning an unknown version succeeded")
+	}
+}
@@ -0,0 +1,287 @@
+package updater
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/xqsit94/glm/internal/config"
+)
+
+const (
+	metadataTimeout = 30 * time.Second
+	downloadTimeout = 10 * time.Minute
+
+	// releasesFile lists the releases of a mirror or static directory in
+	// the format of the GitHub releases API.
+	releasesFile = "releases.json"
+
+	githubTokenEnv = "GITHUB_TOKEN"
+)
+
+// httpClient honors HTTPS_PROXY/NO_PROXY and gives up on unresponsive
+// servers instead of hanging forever.
+var httpClient = &http.Client{
+	Transport: &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		DialContext: (&net.Dialer{
+			Timeout:   10 * time.Second,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ResponseHeaderTimeout: 30 * time.Second,
+		IdleConnTimeout:       90 * time.Second,
+	},
+}
+
+// Source is where releases and their assets are fetched from.
+type Source interface {
+	Name() string
+	// Releases lists every release the source knows about, in any order.
+	Releases() ([]ReleaseInfo, error)
+	// Open returns the asset called name from release version and its size,
+	// or -1 when the size is unknown. A missing asset matches fs.ErrNotExist.
+	Open(version, name string) (io.ReadCloser, int64, error)
+}
+
+// ConfiguredSource returns the source named by GLM_UPDATE_MIRROR, then the
+// update_mirror config key, defaulting to GitHub.
+func ConfiguredSource() (Source, error) {
+	if spec := os.Getenv(config.UpdateMirrorEnv); spec != "" {
+		source, err := NewSource(spec)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", config.UpdateMirrorEnv, err)
+		}
+		return source, nil
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, err
+	}
+	return NewSource(cfg.UpdateMirror)
+}
+
+// NewSource parses a source spec: "github" or "gitee" (optionally
+// ":owner/repo"), an http(s) mirror URL, or a file:// URL or absolute path.
+func NewSource(spec string) (Source, error) {
+	if spec == "" {
+		spec = "github"
+	}
+	if err := config.ValidateUpdateMirror(spec); err != nil {
+		return nil, err
+	}
+
+	kind, repo, _ := strings.Cut(spec, ":")
+	switch kind {
+	case "github":
+		if repo == "" {
+			repo = githubRepo
+		}
+		return newGitHubSource(repo, os.Getenv(githubTokenEnv)), nil
+	case "gitee":
+		if repo == "" {
+			repo = githubRepo
+		}
+		return newGiteeSource(repo), nil
+	}
+
+	if filepath.IsAbs(spec) {
+		return &mirrorSource{base: spec, open: openFile}, nil
+	}
+
+	u, _ := url.Parse(spec)
+	if u.Scheme == "file" {
+		return &mirrorSource{base: u.Path, open: openFile}, nil
+	}
+	return &mirrorSource{base: strings.TrimRight(spec, "/"), open: openURL}, nil
+}
+
+// apiSource talks to a GitHub-compatible releases API.
+type apiSource struct {
+	name         string
+	releasesURL  string
+	downloadBase string
+	pageBase     string
+	token        string
+	tokenEnv     string
+}
+
+func newGitHubSource(repo, token string) *apiSource {
+	name := "GitHub"
+	if repo != githubRepo {
+		name += " (" + repo + ")"
+	}
+	return &apiSource{
+		name:         name,
+		releasesURL:  "https://api.github.com/repos/" + repo + "/releases?per_page=100",
+		downloadBase: "https://github.com/" + repo + "/releases/download",
+		pageBase:     "https://github.com/" + repo + "/releases/tag",
+		token:        token,
+		tokenEnv:     githubTokenEnv,
+	}
+}
+
+func newGiteeSource(repo string) *apiSource {
+	return &apiSource{
+		name:         "Gitee (" + repo + ")",
+		releasesURL:  "https://gitee.com/api/v5/repos/" + repo + "/releases?per_page=100",
+		downloadBase: "https://gitee.com/" + repo + "/releases/download",
+		pageBase:     "https://gitee.com/" + repo + "/releases/tag",
+	}
+}
+
+func (s *apiSource) Name() string {
+	return s.name
+}
+
+func (s *apiSource) Releases() ([]ReleaseInfo, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), metadataTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.releasesURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch release info: %v", err)
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	if s.token != "" {
+		req.Header.Set("Authorization", "Bearer "+s.token)
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch release info: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		if (resp.StatusCode == http.StatusForbidden || resp.StatusCode == http.StatusTooManyRequests) && s.token == "" && s.tokenEnv != "" {
+			return nil, fmt.Errorf("%s API returned status %d; set %s to raise the rate limit", s.name, resp.StatusCode, s.tokenEnv)
+		}
+		return nil, fmt.Errorf("%s API returned status %d", s.name, resp.StatusCode)
+	}
+
+	var releases []ReleaseInfo
+	if err := json.NewDecoder(resp.Body).Decode(&releases); err != nil {
+		return nil, fmt.Errorf("failed to parse release info: %v", err)
+	}
+	for i := range releases {
+		if releases[i].HTMLURL == "" {
+			releases[i].HTMLURL = s.pageBase + "/" + releases[i].TagName
+		}
+	}
+
+	return releases, nil
+}
+
+func (s *apiSource) Open(version, name string) (io.ReadCloser, int64, error) {
+	return openURL(s.downloadBase + "/" + version + "/" + name)
+}
+
+// mirrorSource reads a static layout, over HTTP or from a local directory:
+// releases.json at the top and each release's assets in <tag>/<asset>.
+type mirrorSource struct {
+	base string
+	open func(location string) (io.ReadCloser, int64, error)
+}
+
+func (s *mirrorSource) Name() string {
+	return s.base
+}
+
+func (s *mirrorSource) location(elem ...string) string {
+	if isURL(s.base) {
+		return s.base + "/" + strings.Join(elem, "/")
+	}
+	return filepath.Join(append([]string{s.base}, elem...)...)
+}
+
+func (s *mirrorSource) Releases() ([]ReleaseInfo, error) {
+	body, _, err := s.open(s.location(releasesFile))
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch release info: %v", err)
+	}
+	defer body.Close()
+
+	var releases []ReleaseInfo
+	if err := json.NewDecoder(io.LimitReader(body, 16<<20)).Decode(&releases); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %v", releasesFile, err)
+	}
+
+	for i := range releases {
+		if releases[i].HTMLURL == "" {
+			releases[i].HTMLURL = s.location(releases[i].TagName)
+		}
+	}
+	return releases, nil
+}
+
+func (s *mirrorSource) Open(version, name string) (io.ReadCloser, int64, error) {
+	return s.open(s.location(version, name))
+}
+
+func isURL(location string) bool {
+	return strings.HasPrefix(location, "http://") || strings.HasPrefix(location, "https://")
+}
+
+func openFile(path string) (io.ReadCloser, int64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, 0, err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, 0, err
+	}
+	return f, info.Size(), nil
+}
+
+// openURL starts a GET of rawURL bounded by downloadTimeout; the deadline
+// is released when the body is closed.
+func openURL(rawURL string) (io.ReadCloser, int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), downloadTimeout)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		cancel()
+		return nil, 0, err
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		cancel()
+		return nil, 0, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		cancel()
+		if resp.StatusCode == http.StatusNotFound {
+			return nil, 0, fmt.Errorf("%s returned status %d: %w", rawURL, resp.StatusCode, fs.ErrNotExist)
+		}
+		return nil, 0, fmt.Errorf("%s returned status %d", rawURL, resp.StatusCode)
+	}
+
+	return &cancelOnClose{ReadCloser: resp.Body, cancel: cancel}, resp.ContentLength, nil
+}
+
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (c *cancelOnClose) Close() error {
+	err := c.ReadCloser.Close()
+	c.cancel()
+	return err
+}
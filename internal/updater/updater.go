@@ -1,86 +1,138 @@
 package updater
 
 import (
-	"encoding/json"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"io"
-	"net/http"
 	"os"
 	"path/filepath"
 	"runtime"
-	"strconv"
+	"sort"
 	"strings"
 )
 
+const githubRepo = "xqsit94/glm"
+
 const (
-	githubRepo = "xqsit94/glm"
-	apiURL     = "https://api.github.com/repos/" + githubRepo + "/releases/latest"
+	ChannelStable = "stable"
+	ChannelBeta   = "beta"
 )
 
+var ErrVersionNotFound = errors.New("version not found")
+
 type ReleaseInfo struct {
-	TagName string `json:"tag_name"`
-	Name    string `json:"name"`
-	Body    string `json:"body"`
-	HTMLURL string `json:"html_url"`
+	TagName     string `json:"tag_name"`
+	Name        string `json:"name"`
+	Body        string `json:"body"`
+	HTMLURL     string `json:"html_url"`
+	Draft       bool   `json:"draft"`
+	Prerelease  bool   `json:"prerelease"`
+	PublishedAt string `json:"published_at"`
+	CreatedAt   string `json:"created_at"`
+}
+
+// Date returns the day the release was published as YYYY-MM-DD, or "" when
+// the source does not say.
+func (r *ReleaseInfo) Date() string {
+	date := r.PublishedAt
+	if date == "" {
+		date = r.CreatedAt
+	}
+	if len(date) > len("2006-01-02") {
+		date = date[:len("2006-01-02")]
+	}
+	return date
 }
 
 type UpdateInfo struct {
 	CurrentVersion string
 	LatestVersion  string
 	HasUpdate      bool
+	Downgrade      bool
+	Prerelease     bool
 	ReleaseNotes   string
 	ReleaseURL     string
 }
 
-func GetLatestVersion() (*ReleaseInfo, error) {
-	resp, err := http.Get(apiURL)
-	if err != nil {
-		return nil, fmt.Errorf("failed to fetch release info: %v", err)
+// CheckOptions selects the release to update to: Version pins an exact
+// release, otherwise the newest release on Channel is used.
+type CheckOptions struct {
+	Channel string
+	Version string
+}
+
+func ValidateChannel(channel string) error {
+	if channel != ChannelStable && channel != ChannelBeta {
+		return fmt.Errorf("invalid channel %q: expected %q or %q", channel, ChannelStable, ChannelBeta)
 	}
-	defer resp.Body.Close()
+	return nil
+}
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("GitHub API returned status %d", resp.StatusCode)
+// ListReleases returns the published releases on channel, newest first. The
+// beta channel includes prereleases.
+func ListReleases(source Source, channel string) ([]ReleaseInfo, error) {
+	all, err := source.Releases()
+	if err != nil {
+		return nil, err
 	}
 
-	var release ReleaseInfo
-	if err := json.NewDecoder(resp.Body).Decode(&release); err != nil {
-		return nil, fmt.Errorf("failed to parse release info: %v", err)
+	var releases []ReleaseInfo
+	for _, release := range all {
+		if release.Draft || release.TagName == "" {
+			continue
+		}
+		// Trust the tag over the flag: 1.2.1-rc1 is a prerelease even when
+		// the release was published without being marked as one.
+		if isPrerelease(release.TagName) {
+			release.Prerelease = true
+		}
+		if release.Prerelease && channel != ChannelBeta {
+			continue
+		}
+		releases = append(releases, release)
 	}
 
-	return &release, nil
+	sort.SliceStable(releases, func(i, j int) bool {
+		return CompareVersions(releases[i].TagName, releases[j].TagName) < 0
+	})
+	return releases, nil
 }
 
-func CompareVersions(current, latest string) int {
-	current = strings.TrimPrefix(current, "v")
-	latest = strings.TrimPrefix(latest, "v")
-
-	currentParts := strings.Split(current, ".")
-	latestParts := strings.Split(latest, ".")
-
-	maxLen := len(currentParts)
-	if len(latestParts) > maxLen {
-		maxLen = len(latestParts)
+func GetLatestVersion(source Source, channel string) (*ReleaseInfo, error) {
+	releases, err := ListReleases(source, channel)
+	if err != nil {
+		return nil, err
 	}
+	if len(releases) == 0 {
+		return nil, fmt.Errorf("no %s releases found on %s", channel, source.Name())
+	}
+	return &releases[0], nil
+}
 
-	for i := 0; i < maxLen; i++ {
-		var currentVal, latestVal int
-
-		if i < len(currentParts) {
-			currentVal, _ = strconv.Atoi(currentParts[i])
-		}
-		if i < len(latestParts) {
-			latestVal, _ = strconv.Atoi(latestParts[i])
-		}
+// FindRelease returns the release tagged version, with or without the
+// leading "v". Prereleases can always be pinned.
+func FindRelease(source Source, version string) (*ReleaseInfo, error) {
+	releases, err := ListReleases(source, ChannelBeta)
+	if err != nil {
+		return nil, err
+	}
 
-		if latestVal > currentVal {
-			return 1
-		} else if latestVal < currentVal {
-			return -1
+	want := strings.TrimPrefix(version, "v")
+	for _, release := range releases {
+		if strings.TrimPrefix(release.TagName, "v") == want {
+			return &release, nil
 		}
 	}
+	return nil, fmt.Errorf("%w: %s. Run 'glm update --list --channel beta' to see available versions", ErrVersionNotFound, version)
+}
 
-	return 0
+// CompareVersions returns 1 when latest is newer than current, -1 when it is
+// older and 0 when they are equal, following semver precedence: 1.2.0-rc.1
+// comes before 1.2.0 and build metadata (+...) is ignored.
+func CompareVersions(current, latest string) int {
+	return parseSemver(latest).compare(parseSemver(current))
 }
 
 func DetectPlatform() (string, string, error) {
@@ -98,37 +150,41 @@ func DetectPlatform() (string, string, error) {
 	return osName, arch, nil
 }
 
-func DownloadBinary(version, osName, arch string, progressCallback func(downloaded, total int64)) (string, error) {
-	binaryName := fmt.Sprintf("glm-%s-%s", osName, arch)
-	downloadURL := fmt.Sprintf("https://github.com/%s/releases/download/%s/%s", githubRepo, version, binaryName)
+func BinaryName(osName, arch string) string {
+	return fmt.Sprintf("glm-%s-%s", osName, arch)
+}
+
+// DownloadBinary downloads the release binary to a temporary file and
+// refuses it unless its SHA-256 is expectedSHA256. An empty expectedSHA256
+// skips the check; only pass it when the user explicitly allowed an
+// unverified install.
+func DownloadBinary(source Source, version, osName, arch, expectedSHA256 string, progressCallback func(downloaded, total int64)) (string, error) {
+	binaryName := BinaryName(osName, arch)
 
-	resp, err := http.Get(downloadURL)
+	body, total, err := source.Open(version, binaryName)
 	if err != nil {
 		return "", fmt.Errorf("failed to download binary: %v", err)
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("download failed with status %d", resp.StatusCode)
-	}
+	defer body.Close()
 
-	tmpFile, err := os.CreateTemp("", "glm-update-*")
+	tmpFile, err := createStagingFile()
 	if err != nil {
 		return "", fmt.Errorf("failed to create temp file: %v", err)
 	}
 	defer tmpFile.Close()
 
 	var downloaded int64
-	total := resp.ContentLength
+	hash := sha256.New()
 
 	buf := make([]byte, 32*1024)
 	for {
-		n, err := resp.Body.Read(buf)
+		n, err := body.Read(buf)
 		if n > 0 {
 			if _, writeErr := tmpFile.Write(buf[:n]); writeErr != nil {
 				os.Remove(tmpFile.Name())
 				return "", fmt.Errorf("failed to write to temp file: %v", writeErr)
 			}
+			hash.Write(buf[:n])
 			downloaded += int64(n)
 			if progressCallback != nil {
 				progressCallback(downloaded, total)
@@ -143,10 +199,22 @@ func DownloadBinary(version, osName, arch string, progressCallback func(download
 		}
 	}
 
+	if err := tmpFile.Sync(); err != nil {
+		os.Remove(tmpFile.Name())
+		return "", fmt.Errorf("failed to write to temp file: %v", err)
+	}
+
+	if actual := hex.EncodeToString(hash.Sum(nil)); expectedSHA256 != "" && actual != expectedSHA256 {
+		os.Remove(tmpFile.Name())
+		return "", fmt.Errorf("checksum mismatch for %s: expected %s, got %s", binaryName, expectedSHA256, actual)
+	}
+
 	return tmpFile.Name(), nil
 }
 
-func VerifyBinary(path string) error {
+// VerifyBinary re-checks the downloaded file against its expected SHA-256
+// (unless it is empty, as for DownloadBinary) and makes it executable.
+func VerifyBinary(path, expectedSHA256 string) error {
 	info, err := os.Stat(path)
 	if err != nil {
 		return fmt.Errorf("failed to stat binary: %v", err)
@@ -156,6 +224,16 @@ func VerifyBinary(path string) error {
 		return fmt.Errorf("downloaded binary is empty")
 	}
 
+	if expectedSHA256 != "" {
+		actual, err := fileSHA256(path)
+		if err != nil {
+			return fmt.Errorf("failed to hash binary: %v", err)
+		}
+		if actual != expectedSHA256 {
+			return fmt.Errorf("checksum mismatch: expected %s, got %s", expectedSHA256, actual)
+		}
+	}
+
 	if err := os.Chmod(path, 0755); err != nil {
 		return fmt.Errorf("failed to make binary executable: %v", err)
 	}
@@ -163,34 +241,65 @@ func VerifyBinary(path string) error {
 	return nil
 }
 
-func InstallUpdate(newBinaryPath string) error {
-	currentBinary, err := os.Executable()
+// InstallUpdate smoke-tests the new binary, keeps a copy of the running one
+// for Rollback and then swaps the new binary in.
+func InstallUpdate(newBinaryPath, newVersion, currentVersion string) error {
+	if err := SmokeTest(newBinaryPath, newVersion); err != nil {
+		return fmt.Errorf("new binary failed its smoke test, keeping the current version: %v", err)
+	}
+
+	currentBinary, err := installedBinary(currentVersion)
 	if err != nil {
-		return fmt.Errorf("failed to get current binary path: %v", err)
+		return err
 	}
 
-	currentBinary, err = filepath.EvalSymlinks(currentBinary)
+	if _, err := SaveVersion(currentBinary, currentVersion); err != nil {
+		return err
+	}
+
+	return replaceExecutable(newBinaryPath)
+}
+
+// installedBinary returns the path of the running binary after checking
+// that it still reports currentVersion, so a run that started before another
+// update finished never saves the new binary under the old version's name.
+func installedBinary(currentVersion string) (string, error) {
+	currentBinary, err := currentExecutable()
 	if err != nil {
-		return fmt.Errorf("failed to resolve binary path: %v", err)
+		return "", err
 	}
 
-	backupPath := currentBinary + ".old"
-	if err := os.Rename(currentBinary, backupPath); err != nil {
-		return fmt.Errorf("failed to backup current binary: %v", err)
+	if err := SmokeTest(currentBinary, currentVersion); err != nil {
+		return "", fmt.Errorf("the installed glm changed while this command was running (%v). Run it again", err)
 	}
+	return currentBinary, nil
+}
 
-	if err := os.Rename(newBinaryPath, currentBinary); err != nil {
-		os.Rename(backupPath, currentBinary)
-		return fmt.Errorf("failed to install new binary: %v", err)
+func currentExecutable() (string, error) {
+	currentBinary, err := os.Executable()
+	if err != nil {
+		return "", fmt.Errorf("failed to get current binary path: %v", err)
 	}
 
-	os.Remove(backupPath)
+	currentBinary, err = filepath.EvalSymlinks(currentBinary)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve binary path: %v", err)
+	}
 
-	return nil
+	return currentBinary, nil
 }
 
-func CheckForUpdate(currentVersion string) (*UpdateInfo, error) {
-	release, err := GetLatestVersion()
+// CheckForUpdate compares currentVersion with the release picked by opts.
+// A pinned version counts as an update whenever it differs, so it can also
+// downgrade.
+func CheckForUpdate(source Source, currentVersion string, opts CheckOptions) (*UpdateInfo, error) {
+	var release *ReleaseInfo
+	var err error
+	if opts.Version != "" {
+		release, err = FindRelease(source, opts.Version)
+	} else {
+		release, err = GetLatestVersion(source, opts.Channel)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -198,12 +307,18 @@ func CheckForUpdate(currentVersion string) (*UpdateInfo, error) {
 	info := &UpdateInfo{
 		CurrentVersion: currentVersion,
 		LatestVersion:  release.TagName,
+		Prerelease:     release.Prerelease,
 		ReleaseNotes:   release.Body,
 		ReleaseURL:     release.HTMLURL,
 	}
 
 	comparison := CompareVersions(currentVersion, release.TagName)
-	info.HasUpdate = comparison > 0
+	if opts.Version != "" {
+		info.HasUpdate = comparison != 0
+		info.Downgrade = comparison < 0
+	} else {
+		info.HasUpdate = comparison > 0
+	}
 
 	return info, nil
 }
@@ -0,0 +1,149 @@
+package updater
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"strings"
+)
+
+const (
+	checksumsFile = "checksums.txt"
+	signatureFile = checksumsFile + ".sig"
+)
+
+// signingPublicKey is the base64 ed25519 key that signs every release's
+// checksums.txt. It is a placeholder until the maintainer sets it to the
+// public half of the GLM_SIGNING_KEY secret (see "Signing releases" in the
+// README); while it is empty, releases are not signed and only their
+// checksums are checked.
+const signingPublicKey = ""
+
+// ErrNoChecksums means the release predates published checksums, so its
+// binaries cannot be verified.
+var ErrNoChecksums = errors.New("release has no " + checksumsFile + "; only releases published since glm started publishing checksums can be verified")
+
+// Checksums maps release asset names to their hex SHA-256 digests.
+type Checksums map[string]string
+
+func (c Checksums) Lookup(name string) (string, error) {
+	sum, ok := c[name]
+	if !ok {
+		return "", fmt.Errorf("%s has no entry for %s", checksumsFile, name)
+	}
+	return sum, nil
+}
+
+// SignedReleases reports whether this build verifies release signatures.
+func SignedReleases() bool {
+	return signingPublicKey != ""
+}
+
+// FetchChecksums downloads checksums.txt for version and, when the build has
+// a signing key, verifies its signature. A missing or invalid signature is
+// an error whatever the source.
+func FetchChecksums(source Source, version string) (Checksums, error) {
+	return fetchChecksums(source, version, signingPublicKey)
+}
+
+func fetchChecksums(source Source, version, publicKey string) (Checksums, error) {
+	data, err := fetchAsset(source, version, checksumsFile)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrNoChecksums
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to download %s: %v", checksumsFile, err)
+	}
+
+	if publicKey == "" {
+		return parseChecksums(data)
+	}
+
+	signature, err := fetchAsset(source, version, signatureFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download %s: %v", signatureFile, err)
+	}
+	if err := verifySignature(publicKey, data, signature); err != nil {
+		return nil, err
+	}
+
+	return parseChecksums(data)
+}
+
+func fetchAsset(source Source, version, name string) ([]byte, error) {
+	body, _, err := source.Open(version, name)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	return io.ReadAll(io.LimitReader(body, 1<<20))
+}
+
+func verifySignature(encodedKey string, data, signature []byte) error {
+	publicKey, err := base64.StdEncoding.DecodeString(encodedKey)
+	if err != nil || len(publicKey) != ed25519.PublicKeySize {
+		return fmt.Errorf("release signing key is invalid")
+	}
+
+	sig, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(signature)))
+	if err != nil || len(sig) != ed25519.SignatureSize {
+		return fmt.Errorf("%s is malformed", signatureFile)
+	}
+
+	if !ed25519.Verify(publicKey, data, sig) {
+		return fmt.Errorf("%s signature does not match; refusing to update", checksumsFile)
+	}
+	return nil
+}
+
+// parseChecksums reads sha256sum output ("<hex>  <name>" per line).
+func parseChecksums(data []byte) (Checksums, error) {
+	checksums := make(Checksums)
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("malformed %s line: %q", checksumsFile, line)
+		}
+
+		sum := strings.ToLower(fields[0])
+		if decoded, err := hex.DecodeString(sum); err != nil || len(decoded) != sha256.Size {
+			return nil, fmt.Errorf("malformed checksum in %s: %q", checksumsFile, line)
+		}
+		checksums[strings.TrimPrefix(fields[1], "*")] = sum
+	}
+
+	if len(checksums) == 0 {
+		return nil, fmt.Errorf("%s is empty", checksumsFile)
+	}
+	return checksums, nil
+}
+
+func fileSHA256(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
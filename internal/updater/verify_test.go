@@ -0,0 +1,238 @@
+package updater
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func sha256Hex(data string) string {
+	sum := sha256.Sum256([]byte(data))
+	return hex.EncodeToString(sum[:])
+}
+
+// serveRelease serves a mirror with the given files under /v1.2.0/.
+func serveRelease(t *testing.T, files map[string]string) Source {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name, ok := strings.CutPrefix(r.URL.Path, "/v1.2.0/")
+		content, found := files[name]
+		if !ok || !found {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(content))
+	}))
+	t.Cleanup(server.Close)
+
+	source, err := NewSource(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return source
+}
+
+func TestParseChecksums(t *testing.T) {
+	linux := sha256Hex("linux")
+	darwin := sha256Hex("darwin")
+
+	checksums, err := parseChecksums([]byte(linux + "  glm-linux-amd64\n" + strings.ToUpper(darwin) + " *glm-darwin-arm64\n\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if checksums["glm-linux-amd64"] != linux {
+		t.Errorf("glm-linux-amd64 = %q", checksums["glm-linux-amd64"])
+	}
+	if checksums["glm-darwin-arm64"] != darwin {
+		t.Errorf("binary-mode entry = %q, want lowercase %q", checksums["glm-darwin-arm64"], darwin)
+	}
+	if _, err := checksums.Lookup("glm-linux-arm64"); err == nil {
+		t.Error("Lookup of a missing asset succeeded")
+	}
+
+	for name, data := range map[string]string{
+		"empty":       "\n\n",
+		"one field":   linux + "\n",
+		"three":       linux + "  glm-linux-amd64 extra\n",
+		"short hash":  "abcd  glm-linux-amd64\n",
+		"not hex":     strings.Repeat("z", 64) + "  glm-linux-amd64\n",
+		"html portal": "<html><body>Sign in</body></html>\n",
+	} {
+		if _, err := parseChecksums([]byte(data)); err == nil {
+			t.Errorf("%s: parseChecksums accepted %q", name, data)
+		}
+	}
+}
+
+func TestVerifySignature(t *testing.T) {
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherKey, _, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data := []byte(sha256Hex("linux") + "  glm-linux-amd64\n")
+	signature := []byte(base64.StdEncoding.EncodeToString(ed25519.Sign(privateKey, data)) + "\n")
+	encodedKey := base64.StdEncoding.EncodeToString(publicKey)
+
+	if err := verifySignature(encodedKey, data, signature); err != nil {
+		t.Fatalf("valid signature rejected: %v", err)
+	}
+
+	tampered := []byte(sha256Hex("evil") + "  glm-linux-amd64\n")
+	if err := verifySignature(encodedKey, tampered, signature); err == nil {
+		t.Error("signature accepted for tampered checksums")
+	}
+	if err := verifySignature(base64.StdEncoding.EncodeToString(otherKey), data, signature); err == nil {
+		t.Error("signature accepted with the wrong key")
+	}
+	if err := verifySignature(encodedKey, data, []byte("not base64!")); err == nil {
+		t.Error("malformed signature accepted")
+	}
+	if err := verifySignature("short", data, signature); err == nil {
+		t.Error("invalid public key accepted")
+	}
+}
+
+func TestEmbeddedSigningKeyIsValid(t *testing.T) {
+	if !SignedReleases() {
+		t.Skip("signingPublicKey is not set")
+	}
+	key, err := base64.StdEncoding.DecodeString(signingPublicKey)
+	if err != nil || len(key) != ed25519.PublicKeySize {
+		t.Fatalf("signingPublicKey is not a base64 ed25519 public key")
+	}
+}
+
+func TestFetchChecksumsRequiresSignature(t *testing.T) {
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	encodedKey := base64.StdEncoding.EncodeToString(publicKey)
+	checksums := sha256Hex("binary") + "  glm-linux-amd64\n"
+
+	_, err = fetchChecksums(serveRelease(t, map[string]string{}), "v1.2.0", encodedKey)
+	if !errors.Is(err, ErrNoChecksums) {
+		t.Errorf("release without checksums: err = %v, want ErrNoChecksums", err)
+	}
+
+	_, err = fetchChecksums(serveRelease(t, map[string]string{checksumsFile: checksums}), "v1.2.0", encodedKey)
+	if err == nil || errors.Is(err, ErrNoChecksums) {
+		t.Errorf("missing signature: err = %v, want a hard failure", err)
+	}
+
+	_, err = fetchChecksums(serveRelease(t, map[string]string{
+		checksumsFile: checksums,
+		signatureFile: base64.StdEncoding.EncodeToString(make([]byte, ed25519.SignatureSize)),
+	}), "v1.2.0", encodedKey)
+	if err == nil {
+		t.Error("checksums with a forged signature were accepted")
+	}
+
+	got, err := fetchChecksums(serveRelease(t, map[string]string{
+		checksumsFile: checksums,
+		signatureFile: base64.StdEncoding.EncodeToString(ed25519.Sign(privateKey, []byte(checksums))),
+	}), "v1.2.0", encodedKey)
+	if err != nil {
+		t.Fatalf("signed checksums rejected: %v", err)
+	}
+	if got["glm-linux-amd64"] != sha256Hex("binary") {
+		t.Errorf("glm-linux-amd64 = %q", got["glm-linux-amd64"])
+	}
+}
+
+func TestFetchChecksumsWithoutSigningKey(t *testing.T) {
+	checksums := sha256Hex("binary") + "  glm-linux-amd64\n"
+
+	got, err := fetchChecksums(serveRelease(t, map[string]string{checksumsFile: checksums}), "v1.2.0", "")
+	if err != nil {
+		t.Fatalf("unsigned checksums rejected by a build without a key: %v", err)
+	}
+	if got["glm-linux-amd64"] != sha256Hex("binary") {
+		t.Errorf("glm-linux-amd64 = %q", got["glm-linux-amd64"])
+	}
+
+	_, err = fetchChecksums(serveRelease(t, map[string]string{}), "v1.2.0", "")
+	if !errors.Is(err, ErrNoChecksums) {
+		t.Errorf("release without checksums: err = %v, want ErrNoChecksums", err)
+	}
+}
+
+func TestDownloadBinary(t *testing.T) {
+	const content = "#!/bin/sh\necho glm version 1.2.0\n"
+	source := serveRelease(t, map[string]string{"glm-linux-amd64": content})
+
+	path, err := DownloadBinary(source, "v1.2.0", "linux", "amd64", sha256Hex(content), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(path)
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != content {
+		t.Errorf("downloaded %q, want %q", data, content)
+	}
+	if err := VerifyBinary(path, sha256Hex(content)); err != nil {
+		t.Errorf("VerifyBinary: %v", err)
+	}
+	if err := VerifyBinary(path, sha256Hex("other")); err == nil {
+		t.Error("VerifyBinary accepted the wrong checksum")
+	}
+}
+
+func TestDownloadBinaryRejectsMismatch(t *testing.T) {
+	source := serveRelease(t, map[string]string{"glm-linux-amd64": "<html>captive portal</html>"})
+	before := stagedFiles(t)
+
+	path, err := DownloadBinary(source, "v1.2.0", "linux", "amd64", sha256Hex("the real binary"), nil)
+	if err == nil {
+		os.Remove(path)
+		t.Fatal("DownloadBinary accepted a file with the wrong checksum")
+	}
+	if !strings.Contains(err.Error(), "checksum mismatch") {
+		t.Errorf("err = %v, want a checksum mismatch", err)
+	}
+	if after := stagedFiles(t); after > before {
+		t.Errorf("the rejected download was left behind")
+	}
+}
+
+// stagedFiles counts download staging files next to the test binary, where
+// createStagingFile puts them.
+func stagedFiles(t *testing.T) int {
+	t.Helper()
+	exe, err := currentExecutable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	matches, err := filepath.Glob(filepath.Join(filepath.Dir(exe), ".glm-update-*"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return len(matches)
+}
+
+func TestDownloadBinaryMissingAsset(t *testing.T) {
+	source := serveRelease(t, map[string]string{})
+
+	if path, err := DownloadBinary(source, "v1.2.0", "linux", "amd64", sha256Hex("x"), nil); err == nil {
+		os.Remove(path)
+		t.Fatal("DownloadBinary succeeded for a missing asset")
+	}
+}
@@ -0,0 +1,142 @@
+package updater
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/xqsit94/glm/pkg/paths"
+)
+
+const (
+	smokeTestTimeout = 10 * time.Second
+	keepVersions     = 3
+	savedPrefix      = "glm-"
+)
+
+type SavedVersion struct {
+	Version string
+	Path    string
+	SavedAt time.Time
+}
+
+// SmokeTest runs the binary at path with --version and checks that it
+// reports expectedVersion.
+func SmokeTest(path, expectedVersion string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), smokeTestTimeout)
+	defer cancel()
+
+	out, err := exec.CommandContext(ctx, path, "--version").CombinedOutput()
+	if ctx.Err() == context.DeadlineExceeded {
+		return fmt.Errorf("'glm --version' did not finish within %s", smokeTestTimeout)
+	}
+	if err != nil {
+		return fmt.Errorf("'glm --version' failed: %v", err)
+	}
+
+	expected := strings.TrimPrefix(expectedVersion, "v")
+	for _, field := range strings.Fields(string(out)) {
+		if strings.TrimPrefix(field, "v") == expected {
+			return nil
+		}
+	}
+	return fmt.Errorf("binary reports %q, expected version %s", strings.TrimSpace(string(out)), expected)
+}
+
+// SaveVersion copies the binary at path into the versions directory so it
+// can be restored with Rollback. Only the newest few copies are kept.
+func SaveVersion(path, version string) (string, error) {
+	dir := paths.GetVersionsDir()
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", fmt.Errorf("failed to create versions directory: %v", err)
+	}
+
+	dest := filepath.Join(dir, savedPrefix+strings.TrimPrefix(version, "v"))
+	if err := copyFile(path, dest, 0755); err != nil {
+		return "", fmt.Errorf("failed to save current binary: %v", err)
+	}
+	now := time.Now()
+	os.Chtimes(dest, now, now)
+
+	saved, err := SavedVersions()
+	if err == nil {
+		for _, old := range saved[min(len(saved), keepVersions):] {
+			os.Remove(old.Path)
+		}
+	}
+
+	return dest, nil
+}
+
+// SavedVersions lists the binaries kept by SaveVersion, newest first.
+func SavedVersions() ([]SavedVersion, error) {
+	entries, err := os.ReadDir(paths.GetVersionsDir())
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read versions directory: %v", err)
+	}
+
+	var saved []SavedVersion
+	for _, entry := range entries {
+		version, ok := strings.CutPrefix(entry.Name(), savedPrefix)
+		if !ok || entry.IsDir() {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		saved = append(saved, SavedVersion{
+			Version: version,
+			Path:    filepath.Join(paths.GetVersionsDir(), entry.Name()),
+			SavedAt: info.ModTime(),
+		})
+	}
+
+	sort.Slice(saved, func(i, j int) bool { return saved[i].SavedAt.After(saved[j].SavedAt) })
+	return saved, nil
+}
+
+// Rollback reinstalls the most recently saved binary whose version differs
+// from currentVersion and returns that version.
+func Rollback(currentVersion string) (string, error) {
+	saved, err := SavedVersions()
+	if err != nil {
+		return "", err
+	}
+
+	current := strings.TrimPrefix(currentVersion, "v")
+	for _, candidate := range saved {
+		if candidate.Version == current {
+			continue
+		}
+
+		if err := SmokeTest(candidate.Path, candidate.Version); err != nil {
+			return "", fmt.Errorf("saved binary %s does not work: %v", candidate.Path, err)
+		}
+
+		currentBinary, err := installedBinary(currentVersion)
+		if err != nil {
+			return "", err
+		}
+
+		staged, err := stageFile(candidate.Path, filepath.Dir(currentBinary), 0755)
+		if err != nil {
+			return "", fmt.Errorf("failed to stage saved binary: %v", err)
+		}
+
+		if err := replaceExecutable(staged); err != nil {
+			return "", err
+		}
+		return candidate.Version, nil
+	}
+
+	return "", fmt.Errorf("no previous version saved in %s", paths.GetVersionsDir())
+}
@@ -0,0 +1,96 @@
+package vault
+
+import (
+	"bufio"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const agentTimeout = 2 * time.Second
+
+// Serve holds key in memory and hands it to clients on socketPath until ttl
+// expires or a client asks it to stop.
+func Serve(socketPath string, key []byte, ttl time.Duration) error {
+	if err := os.MkdirAll(filepath.Dir(socketPath), 0700); err != nil {
+		return fmt.Errorf("failed to create agent directory: %v", err)
+	}
+
+	Stop(socketPath)
+	os.Remove(socketPath)
+
+	listener, err := net.Listen("unix", socketPath)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %v", socketPath, err)
+	}
+	defer listener.Close()
+
+	if err := os.Chmod(socketPath, 0600); err != nil {
+		return fmt.Errorf("failed to secure agent socket: %v", err)
+	}
+
+	// Closing the listener also removes the socket file.
+	timer := time.AfterFunc(ttl, func() { listener.Close() })
+	defer timer.Stop()
+
+	encoded := hex.EncodeToString(key)
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			return nil
+		}
+
+		conn.SetDeadline(time.Now().Add(agentTimeout))
+		request, _ := bufio.NewReader(conn).ReadString('\n')
+		switch strings.TrimSpace(request) {
+		case "key":
+			fmt.Fprintln(conn, encoded)
+		case "stop":
+			// Release the socket before replying so a replacement agent
+			// can bind it as soon as Stop returns.
+			listener.Close()
+			fmt.Fprintln(conn, "ok")
+			conn.Close()
+			return nil
+		}
+		conn.Close()
+	}
+}
+
+// FetchKey returns the key cached by a running agent.
+func FetchKey(socketPath string) ([]byte, error) {
+	reply, err := request(socketPath, "key")
+	if err != nil {
+		return nil, err
+	}
+	return hex.DecodeString(reply)
+}
+
+// Stop asks a running agent to forget its key and exit.
+func Stop(socketPath string) bool {
+	reply, err := request(socketPath, "stop")
+	return err == nil && reply == "ok"
+}
+
+func request(socketPath, command string) (string, error) {
+	conn, err := net.DialTimeout("unix", socketPath, agentTimeout)
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+
+	conn.SetDeadline(time.Now().Add(agentTimeout))
+	if _, err := fmt.Fprintln(conn, command); err != nil {
+		return "", err
+	}
+
+	reply, err := bufio.NewReader(conn).ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(reply), nil
+}
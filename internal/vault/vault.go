@@ -0,0 +1,142 @@
+package vault
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/xqsit94/glm/internal/config"
+)
+
+const (
+	KDF               = "pbkdf2-sha256"
+	DefaultIterations = 600000
+	keySize           = 32
+	checkName         = "glm-vault-check"
+)
+
+var ErrWrongPassphrase = errors.New("wrong passphrase")
+
+// New creates vault parameters for passphrase and returns them with the
+// derived key.
+func New(passphrase string) (*config.Vault, []byte, error) {
+	if passphrase == "" {
+		return nil, nil, fmt.Errorf("passphrase cannot be empty")
+	}
+
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, nil, fmt.Errorf("failed to generate salt: %v", err)
+	}
+
+	v := &config.Vault{
+		KDF:        KDF,
+		Iterations: DefaultIterations,
+		Salt:       base64.StdEncoding.EncodeToString(salt),
+	}
+
+	key, err := derive(v, passphrase)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	v.Check, err = Encrypt(key, checkName, checkName)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return v, key, nil
+}
+
+// Unlock derives the key for passphrase and checks it against the vault.
+func Unlock(v *config.Vault, passphrase string) ([]byte, error) {
+	key, err := derive(v, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	if err := Verify(v, key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+func Verify(v *config.Vault, key []byte) error {
+	check, err := Decrypt(key, checkName, v.Check)
+	if err != nil || subtle.ConstantTimeCompare([]byte(check), []byte(checkName)) != 1 {
+		return ErrWrongPassphrase
+	}
+	return nil
+}
+
+func derive(v *config.Vault, passphrase string) ([]byte, error) {
+	if v.KDF != KDF {
+		return nil, fmt.Errorf("unsupported key derivation %q", v.KDF)
+	}
+
+	salt, err := base64.StdEncoding.DecodeString(v.Salt)
+	if err != nil || len(salt) == 0 || v.Iterations <= 0 {
+		return nil, fmt.Errorf("invalid vault parameters in config")
+	}
+
+	return pbkdf2.Key(sha256.New, passphrase, salt, v.Iterations, keySize)
+}
+
+// Encrypt seals plaintext with AES-GCM. The token name is bound as
+// additional data so values cannot be swapped between names.
+func Encrypt(key []byte, name, plaintext string) (string, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("failed to generate nonce: %v", err)
+	}
+
+	sealed := gcm.Seal(nonce, nonce, []byte(plaintext), []byte(name))
+	return config.EncryptedTokenPrefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+func Decrypt(key []byte, name, value string) (string, error) {
+	encoded, ok := strings.CutPrefix(value, config.EncryptedTokenPrefix)
+	if !ok {
+		return "", fmt.Errorf("token %q is not encrypted", name)
+	}
+
+	sealed, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", fmt.Errorf("token %q is corrupted", name)
+	}
+
+	gcm, err := newGCM(key)
+	if err != nil {
+		return "", err
+	}
+	if len(sealed) < gcm.NonceSize() {
+		return "", fmt.Errorf("token %q is corrupted", name)
+	}
+
+	nonce, ciphertext := sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():]
+	plaintext, err := gcm.Open(nil, nonce, ciphertext, []byte(name))
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt token %q", name)
+	}
+
+	return string(plaintext), nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid vault key: %v", err)
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,222 @@
+package vault
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/xqsit94/glm/internal/config"
+)
+
+// newTestVault is New with few iterations, so tests stay fast.
+func newTestVault(t *testing.T, passphrase string) (*config.Vault, []byte) {
+	t.Helper()
+	v := &config.Vault{KDF: KDF, Iterations: 1000, Salt: "c2FsdHNhbHRzYWx0c2FsdA=="}
+	key, err := derive(v, passphrase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v.Check, err = Encrypt(key, checkName, checkName); err != nil {
+		t.Fatal(err)
+	}
+	return v, key
+}
+
+func TestNew(t *testing.T) {
+	v, key, err := New("correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v.KDF != KDF || v.Iterations != DefaultIterations {
+		t.Errorf("New = %+v", v)
+	}
+	if err := Verify(v, key); err != nil {
+		t.Fatalf("Verify rejected the key from New: %v", err)
+	}
+
+	if _, _, err := New(""); err == nil {
+		t.Error("New accepted an empty passphrase")
+	}
+}
+
+func TestEncryptDecrypt(t *testing.T) {
+	_, key := newTestVault(t, "correct horse")
+
+	sealed, err := Encrypt(key, "work", "abc.def")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !config.IsEncryptedToken(sealed) || strings.Contains(sealed, "abc.def") {
+		t.Fatalf("Encrypt returned %q", sealed)
+	}
+
+	again, err := Encrypt(key, "work", "abc.def")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again == sealed {
+		t.Error("two encryptions of the same token are identical; the nonce is reused")
+	}
+
+	plaintext, err := Decrypt(key, "work", sealed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plaintext != "abc.def" {
+		t.Errorf("Decrypt = %q, want abc.def", plaintext)
+	}
+
+	if _, err := Decrypt(key, "work", "abc.def"); err == nil {
+		t.Error("Decrypt accepted a plaintext value")
+	}
+	if _, err := Decrypt(key, "work", config.EncryptedTokenPrefix+"AAAA"); err == nil {
+		t.Error("Decrypt accepted a truncated value")
+	}
+}
+
+func TestUnlock(t *testing.T) {
+	v, key := newTestVault(t, "correct horse")
+
+	unlocked, err := Unlock(v, "correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(unlocked, key) {
+		t.Error("Unlock derived a different key")
+	}
+
+	if _, err := Unlock(v, "wrong horse"); !errors.Is(err, ErrWrongPassphrase) {
+		t.Errorf("wrong passphrase: err = %v, want ErrWrongPassphrase", err)
+	}
+}
+
+func TestDecryptBindsTokenName(t *testing.T) {
+	_, key := newTestVault(t, "correct horse")
+
+	sealed, err := Encrypt(key, "personal", "abc.def")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Decrypt(key, "team", sealed); err == nil {
+		t.Error("a token moved to another name was decrypted")
+	}
+
+	_, otherKey := newTestVault(t, "another passphrase")
+	if _, err := Decrypt(otherKey, "personal", sealed); err == nil {
+		t.Error("a token was decrypted with another vault's key")
+	}
+}
+
+func TestParseRejectsPlaintextWithVault(t *testing.T) {
+	v, key := newTestVault(t, "correct horse")
+	sealed, err := Encrypt(key, "work", "abc.def")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct {
+		name   string
+		tokens map[string]string
+		valid  bool
+	}{
+		{"encrypted", map[string]string{"work": sealed}, true},
+		{"plaintext", map[string]string{"work": "abc.def"}, false},
+		{"mixed", map[string]string{"work": sealed, "team": "ghi.jkl"}, false},
+	} {
+		data, err := json.Marshal(&config.Config{Vault: v, Tokens: tt.tokens})
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = config.Parse(data)
+		if tt.valid && err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+		}
+		if !tt.valid && err == nil {
+			t.Errorf("%s: Parse accepted a plaintext token while a vault is set", tt.name)
+		}
+	}
+}
+
+func TestAgent(t *testing.T) {
+	// Unix socket paths are limited to about 100 bytes, which t.TempDir can
+	// exceed on macOS.
+	dir, err := os.MkdirTemp("", "glm-agent")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	socketPath := filepath.Join(dir, "agent.sock")
+
+	if _, err := FetchKey(socketPath); err == nil {
+		t.Fatal("FetchKey succeeded with no agent running")
+	}
+
+	key := bytes.Repeat([]byte{0x42}, keySize)
+	done := make(chan error, 1)
+	go func() { done <- Serve(socketPath, key, time.Minute) }()
+
+	var fetched []byte
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if fetched, err = FetchKey(socketPath); err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("agent did not start: %v", err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if !bytes.Equal(fetched, key) {
+		t.Errorf("FetchKey = %x, want %x", fetched, key)
+	}
+
+	info, err := os.Stat(socketPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm&0077 != 0 {
+		t.Errorf("agent socket mode = %v, want it private", perm)
+	}
+
+	if !Stop(socketPath) {
+		t.Fatal("Stop did not reach the agent")
+	}
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Serve: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("agent did not exit after Stop")
+	}
+
+	if _, err := FetchKey(socketPath); err == nil {
+		t.Error("FetchKey succeeded after Stop")
+	}
+}
+
+func TestAgentExpires(t *testing.T) {
+	dir, err := os.MkdirTemp("", "glm-agent")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	socketPath := filepath.Join(dir, "agent.sock")
+
+	done := make(chan error, 1)
+	go func() { done <- Serve(socketPath, bytes.Repeat([]byte{1}, keySize), 50*time.Millisecond) }()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("agent outlived its TTL")
+	}
+	if _, err := FetchKey(socketPath); err == nil {
+		t.Error("FetchKey succeeded after the TTL")
+	}
+}
@@ -1,10 +1,12 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"os"
 
 	"github.com/xqsit94/glm/cmd"
+	"github.com/xqsit94/glm/internal/runner"
 )
 
 func main() {
@@ -14,9 +16,20 @@ func main() {
 	rootCmd.AddCommand(cmd.DisableCmd())
 	rootCmd.AddCommand(cmd.InstallCmd())
 	rootCmd.AddCommand(cmd.TokenCmd())
+	rootCmd.AddCommand(cmd.ProfileCmd())
+	rootCmd.AddCommand(cmd.ConfigCmd())
+	rootCmd.AddCommand(cmd.EnvCmd())
+	rootCmd.AddCommand(cmd.ExecCmd())
+	rootCmd.AddCommand(cmd.ProxyCmd())
+	rootCmd.AddCommand(cmd.MigrateCmd())
+	rootCmd.AddCommand(cmd.DoctorCmd())
 	rootCmd.AddCommand(cmd.UpdateCmd())
 
 	if err := rootCmd.Execute(); err != nil {
+		var exitErr *runner.ExitError
+		if errors.As(err, &exitErr) {
+			os.Exit(exitErr.Code)
+		}
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
@@ -1,12 +1,51 @@
 package paths
 
 import (
+	"errors"
 	"os"
+	"os/user"
 	"path/filepath"
 )
 
+const appName = "glm"
+
+var ErrNoHomeDir = errors.New("cannot determine your home directory. Set HOME, or set GLM_HOME and CLAUDE_CONFIG_DIR")
+
+func homeDir() string {
+	if home, err := os.UserHomeDir(); err == nil {
+		return home
+	}
+	if u, err := user.Current(); err == nil && u.HomeDir != "" {
+		return u.HomeDir
+	}
+	return ""
+}
+
+// Check fails when the home directory is unknown and GLM_HOME and
+// CLAUDE_CONFIG_DIR do not make up for it, so files never end up in a
+// relative or shared location.
+func Check() error {
+	if homeDir() != "" {
+		return nil
+	}
+	if os.Getenv("GLM_HOME") != "" && os.Getenv("CLAUDE_CONFIG_DIR") != "" {
+		return nil
+	}
+	return ErrNoHomeDir
+}
+
+func xdgDir(envVar, fallback string) string {
+	if dir := os.Getenv(envVar); filepath.IsAbs(dir) {
+		return filepath.Join(dir, appName)
+	}
+	return filepath.Join(homeDir(), fallback, appName)
+}
+
 func GetClaudeDir() string {
-	return filepath.Join(os.Getenv("HOME"), ".claude")
+	if dir := os.Getenv("CLAUDE_CONFIG_DIR"); dir != "" {
+		return dir
+	}
+	return filepath.Join(homeDir(), ".claude")
 }
 
 func GetClaudeSettingsPath() string {
@@ -14,9 +53,46 @@ func GetClaudeSettingsPath() string {
 }
 
 func GetConfigDir() string {
-	return filepath.Join(os.Getenv("HOME"), ".glm")
+	if dir := os.Getenv("GLM_HOME"); dir != "" {
+		return dir
+	}
+	return xdgDir("XDG_CONFIG_HOME", ".config")
 }
 
 func GetConfigPath() string {
 	return filepath.Join(GetConfigDir(), "config.json")
 }
+
+func GetStateDir() string {
+	if dir := os.Getenv("GLM_HOME"); dir != "" {
+		return filepath.Join(dir, "state")
+	}
+	return xdgDir("XDG_STATE_HOME", filepath.Join(".local", "state"))
+}
+
+func GetCacheDir() string {
+	if dir := os.Getenv("GLM_HOME"); dir != "" {
+		return filepath.Join(dir, "cache")
+	}
+	return xdgDir("XDG_CACHE_HOME", ".cache")
+}
+
+func GetLegacyConfigDir() string {
+	return filepath.Join(homeDir(), ".glm")
+}
+
+func GetLegacyConfigPath() string {
+	return filepath.Join(GetLegacyConfigDir(), "config.json")
+}
+
+func GetAgentSocketPath() string {
+	return filepath.Join(GetStateDir(), "agent.sock")
+}
+
+func GetVersionsDir() string {
+	return filepath.Join(GetStateDir(), "versions")
+}
+
+func GetProxyLogPath() string {
+	return filepath.Join(GetStateDir(), "proxy.log")
+}